@@ -5,12 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/gogs/git-module"
@@ -46,14 +45,29 @@ var (
 
 var timeNow = time.Now
 
-// GitRepoConfig is the configuration needed to create a new *GitRepo.
-type GitRepoConfig struct {
-	// Repo is the path to the root of the git repository.
-	RepoPath string
+// ErrNoRelease is returned by AutoTag when SkipUnreleasable is set and there are no releasable
+// commits since the current version.
+var ErrNoRelease = errors.New("no release needed")
 
-	// Branch is the name of the git branch to be tracked for tags. This value
-	// must be provided.
-	Branch string
+// GitRepoConfig is the configuration needed to create a new *GitRepo. Apart from RepoPath, every
+// field can also be set in a repository config file read by LoadConfigFile, using the key in the
+// field's yaml and toml tags.
+type GitRepoConfig struct {
+	// RepoPath is a path inside the git repository: the root or any subdirectory of its working
+	// tree, including linked worktrees and submodules, or a bare repository. The git directory is
+	// found the way git finds it, eg: by following the "gitdir:" line of a .git file.
+	RepoPath string `yaml:"-" toml:"-"`
+
+	// Promote makes NewRepo promote the newest pre-release tag instead of calculating the next
+	// version, see GitRepo.Promote. No stable version tag is needed, so the first release candidate
+	// of a repository can be promoted. It can't be set in a config file.
+	Promote bool `yaml:"-" toml:"-"`
+
+	// Branch is the name of the git branch to be tracked for tags. Any revision git understands can
+	// be used, eg: "origin/main", "HEAD" or a commit id, and the new tag is created on the commit it
+	// resolves to. If there is no local branch with the name, the remote-tracking branch of origin
+	// is used, eg: "origin/main" for "main". If not specified "main" or "master" is used.
+	Branch string `yaml:"branch" toml:"branch"`
 
 	// PreReleaseName is the optional string to be appended to a tag being
 	// generated (e.g., v.1.2.3-pre) to indicate the pre-release type.
@@ -65,7 +79,7 @@ type GitRepoConfig struct {
 	// 		* alpha
 	// 		* beta
 	// 		* rc
-	PreReleaseName string
+	PreReleaseName string `yaml:"pre-release-name" toml:"pre-release-name"`
 
 	// PreReleaseTimestampLayout is the optional value that's used to append a
 	// timestamp to the git tag. The timezone will always be UTC. This value can
@@ -83,7 +97,16 @@ type GitRepoConfig struct {
 	// that value separated by a period (`.`):
 	//
 	// 		v1.2.3-pre.1499308568
-	PreReleaseTimestampLayout string
+	PreReleaseTimestampLayout string `yaml:"pre-release-timestamp" toml:"pre-release-timestamp"`
+
+	// PreReleaseCounter appends an incrementing counter to PreReleaseName, which must be set. The
+	// counter is one higher than the highest existing pre-release tag with the same name for the
+	// calculated version, so consecutive builds produce:
+	//
+	// 		v1.2.3-rc.1, v1.2.3-rc.2, v1.2.3-rc.3
+	//
+	// It cannot be combined with PreReleaseTimestampLayout.
+	PreReleaseCounter bool `yaml:"pre-release-counter" toml:"pre-release-counter"`
 
 	// BuildMetadata is an optional string appended by a plus sign and a series of dot separated
 	// identifiers immediately following the patch or pre-release version. Identifiers MUST comprise
@@ -92,10 +115,11 @@ type GitRepoConfig struct {
 	// build metadata, have the same precedence. Examples: 1.0.0-alpha+001, 1.0.0+20130313144700,
 	// 1.0.0-beta+exp.sha.5114f85
 	// https://semver.org/#spec-item-10
-	BuildMetadata string
+	BuildMetadata string `yaml:"build-metadata" toml:"build-metadata"`
 
-	// Scheme is the versioning scheme to use when determining the version of the next
-	// tag. If not specified the default "autotag" is used.
+	// Scheme is the name of the versioning scheme to use when determining the version of the next
+	// tag. If not specified the default "autotag" is used. Additional schemes can be made available
+	// with RegisterScheme. The built-in schemes are:
 	//
 	//   * "autotag" (default if not specified):
 	//
@@ -107,29 +131,225 @@ type GitRepoConfig struct {
 	//
 	//   * "conventional" implements the Conventional Commits v1.0.0 scheme.
 	//     * https://www.conventionalcommits.org/en/v1.0.0/#summary w
-	Scheme string
+	Scheme string `yaml:"scheme" toml:"scheme"`
 
 	// Prefix prepends literal 'v' to the tag, eg: v1.0.0. Enabled by default
-	Prefix bool
+	Prefix bool `yaml:"prefix" toml:"prefix"`
+
+	// AnnotatedTag creates an annotated tag, rather than a lightweight tag, when applying the new
+	// version. Annotated tags carry a message and are visible to `git describe` without `--tags`.
+	AnnotatedTag bool `yaml:"annotate" toml:"annotate"`
+
+	// TagMessage is an optional Go text/template used to render the message of an annotated tag. It
+	// is ignored unless AnnotatedTag is set. If not specified a default message listing the commits
+	// included in the release is used. The template is executed with a TagMessageData value:
+	//
+	//	{{.Tag}} ({{.Bump}} bump from {{.PreviousVersion}})
+	//	{{range .Commits}}
+	//	* {{.ID}} {{.Summary}}{{end}}
+	//
+	// https://golang.org/pkg/text/template/
+	TagMessage string `yaml:"tag-message" toml:"tag-message"`
+
+	// SignTag creates a signed tag using git's configured signing program. Signed tags are always
+	// annotated and use the TagMessage template. Once created the tag is checked with
+	// `git verify-tag`, and it is deleted again if the signature does not verify.
+	SignTag bool `yaml:"sign" toml:"sign"`
+
+	// SigningKey is the key used to sign the tag: a GPG key ID for the "openpgp" and "x509" formats,
+	// or the path to a public or private key for the "ssh" format. If not specified git's
+	// `user.signingKey` config is used.
+	SigningKey string `yaml:"signing-key" toml:"signing-key"`
+
+	// SigningFormat overrides git's `gpg.format` config for signing and verifying the tag. It must
+	// be one of "openpgp", "x509" or "ssh". If not specified git's configuration is used.
+	SigningFormat string `yaml:"signing-format" toml:"signing-format"`
+
+	// PushRemote is the name of a git remote, eg: "origin", that AutoTag pushes the new tag to. If
+	// not specified the tag is only created locally.
+	//
+	// If the remote already has a tag with the same name on a different commit, for example because
+	// another pipeline released concurrently, the tags are fetched from the remote, the version is
+	// recalculated and the push is retried.
+	PushRemote string `yaml:"push" toml:"push"`
+
+	// PushRetries is the maximum number of times a push is retried after a conflicting tag was found
+	// on the remote. If not specified no retries are made; the CLI defaults to 3.
+	PushRetries int `yaml:"push-retries" toml:"push-retries"`
+
+	// TagPrefix is an optional prefix for the tags of one component of a monorepo, eg:
+	// "services/api/". Only tags starting with the prefix are considered when looking for the
+	// current version, and the new tag is created with it, eg: services/api/v1.4.2
+	TagPrefix string `yaml:"tag-prefix" toml:"tag-prefix"`
+
+	// Paths optionally limits the commits inspected when calculating the new version to those
+	// touching at least one of the given paths, relative to the root of the repository. Combined
+	// with TagPrefix this allows components of a monorepo to be versioned independently.
+	Paths []string `yaml:"paths" toml:"paths"`
+
+	// AllTags considers version tags anywhere in the repository when looking for the current
+	// version. By default only tags reachable from Branch are considered, so that tags on other
+	// branches, eg: v2.x tags on main, don't affect the version of a release/1.x branch.
+	AllTags bool `yaml:"all-tags" toml:"all-tags"`
+
+	// MaintenanceBranches are patterns matching the names of maintenance branches, which declare
+	// the version line they belong to with {major} and {minor} placeholders, eg:
+	//
+	//   * "release/{major}.{minor}": release/1.4 only allows patch bumps within 1.4.x
+	//   * "support/v{major}": support/v2 allows minor and patch bumps within 2.x
+	//
+	// When Branch matches a pattern, a commit requesting a bump that would leave the line makes
+	// NewRepo return an error instead of calculating a version such as v2.0.0 for release/1.4.
+	MaintenanceBranches []string `yaml:"maintenance-branches" toml:"maintenance-branches"`
+
+	// InitialVersion is the version of the first release of a repository without any version tags,
+	// eg: "0.1.0". If not specified NewRepo returns an error when no version tag is found.
+	//
+	// The whole history of Branch is inspected with the scheme, starting from 0.0.0. If the commits
+	// request a higher version, eg: 1.0.0 for a breaking change, that version is used instead.
+	InitialVersion string `yaml:"initial-version" toml:"initial-version"`
+
+	// Bump forces the version bump, ignoring the bumps requested by commit messages. It must be one
+	// of "major", "minor" or "patch". If not specified the scheme determines the bump.
+	Bump string `yaml:"bump" toml:"bump"`
+
+	// Version is an explicit version for the new tag, eg: "2.0.0", instead of a version calculated
+	// from the commits. It must be higher than the current version and cannot be combined with Bump.
+	Version string `yaml:"set-version" toml:"set-version"`
+
+	// InitialDevelopment applies SemVer's initial development rules to 0.y.z versions, where anything
+	// may change: a commit requesting a major bump bumps the minor version, eg: 0.3.1 -> 0.4.0, and a
+	// commit requesting a minor bump bumps the patch version. Releasing 1.0.0 then requires forcing
+	// a major Bump or setting the Version explicitly.
+	InitialDevelopment bool `yaml:"initial-development" toml:"initial-development"`
+
+	// ConventionalTypes overrides the bump requested by conventional commit types when using the
+	// "conventional" scheme. It maps a commit type to "major", "minor", "patch" or "none", eg:
+	//
+	//	map[string]string{"perf": "minor", "docs": "none", "chore": "none"}
+	//
+	// Types that aren't listed keep their default: "feat" is a minor bump and other types don't
+	// request a bump. Breaking changes are always a major bump.
+	ConventionalTypes map[string]string `yaml:"conventional-types" toml:"conventional-types"`
+
+	// SkipUnreleasable reports that no release is needed, rather than applying a patch bump, when
+	// there are no commits since the current version or none of them warrant a release, ie: they all
+	// have BumpNone. LatestVersion then returns the current version and AutoTag returns ErrNoRelease
+	// without creating a tag, so running autotag twice on the same commit doesn't tag it twice.
+	SkipUnreleasable bool `yaml:"skip-unreleasable" toml:"skip-unreleasable"`
+
+	// AlwaysTag calculates and creates a new tag even if the commit of Branch already has a version
+	// tag. By default a version tag on the commit with the same TagPrefix, 'v' prefix and kind of
+	// version, ie: stable or a pre-release with PreReleaseName, is returned as the new version and
+	// AutoTag doesn't create another one, so retried CI jobs don't release the same commit twice.
+	AlwaysTag bool `yaml:"always-tag" toml:"always-tag"`
+
+	// DeepenShallow fetches the tags and deepens the history of a shallow clone from origin until
+	// a version tag is reachable from Branch. By default NewRepo returns an error for shallow
+	// clones, because tags and the history since the last version tag may be missing.
+	DeepenShallow bool `yaml:"deepen-shallow" toml:"deepen-shallow"`
+
+	// Backend selects how the repository is read and tagged:
+	//   * "git" (default) runs the git CLI.
+	//   * "go-git" uses the pure Go implementation of go-git, so git doesn't need to be installed
+	//     unless tags are pushed or signed, Paths is set or a shallow clone is deepened.
+	Backend string `yaml:"backend" toml:"backend"`
+}
+
+// TagMessageData is the data available to the TagMessage template when rendering the message of an
+// annotated tag.
+type TagMessageData struct {
+	// Tag is the name of the tag being created, including the 'v' prefix if enabled, eg: v1.2.3
+	Tag string
+
+	// Version is the new version, eg: 1.2.3
+	Version string
+
+	// PreviousVersion is the version the new version was calculated from, eg: 1.2.2. It is 0.0.0
+	// for the first release of a repository without version tags.
+	PreviousVersion string
+
+	// Bump is the level of the version bump applied: "major", "minor" or "patch". It is empty when
+	// a pre-release was promoted or the initial version was used as is.
+	Bump string
+
+	// Commits are the commits between the previous version and the new tag, in chronological order.
+	Commits []*git.Commit
+}
+
+// defaultTagMessage is the TagMessage template used when none is provided.
+const defaultTagMessage = `Release {{.Tag}}
+{{if .Bump}}
+{{.Bump}} bump from {{.PreviousVersion}}
+{{end}}{{range .Commits}}
+* {{.ID}} {{.Summary}}{{end}}
+`
+
+// inspectedCommit is a commit inspected by calcVersion and the bump its message requested
+type inspectedCommit struct {
+	*git.Commit
+	bump BumpLevel
 }
 
 // GitRepo represents a repository we want to run actions against
 type GitRepo struct {
-	repo *git.Repository
+	repo     repository
+	gitDir   string // path to the git directory, where the git CLI runs
+	workTree string // path to the root of the work tree, empty for a bare repository
 
 	currentVersion *version.Version
 	currentTag     *git.Commit
+	currentTagName string
 	newVersion     *version.Version
 	branch         string
 	branchID       string // commit id of the branch latest commit (where we will apply the tag)
 
 	preReleaseName            string
 	preReleaseTimestampLayout string
+	preReleaseCounter         bool
 	buildMetadata             string
 
-	scheme string
+	scheme     Scheme
+	schemeName string
 
 	prefix bool
+
+	annotatedTag  bool
+	tagMessage    *template.Template
+	signTag       bool
+	signingKey    string
+	signingFormat string
+
+	pushRemote  string
+	pushRetries int
+
+	tagPrefix string
+	paths     []string
+	allTags   bool
+
+	maintenanceLine *maintenanceLine
+
+	initialVersion *version.Version
+
+	forceBump       BumpLevel
+	explicitVersion *version.Version
+
+	initialDevelopment bool
+
+	skipUnreleasable bool
+	noRelease        bool // no commit since currentTag warrants a release, newVersion is currentVersion
+
+	alwaysTag   bool
+	existingTag string // version tag already on branchID, which is the new version
+
+	deepenShallow bool
+
+	bumpCommit *git.Commit // the first commit requesting the bump applied, nil for a fallback patch bump
+
+	promoted bool // newVersion was set by Promote rather than calculated from commits
+
+	bump    BumpLevel         // the bump applied to currentVersion to calculate newVersion
+	commits []inspectedCommit // commits between currentTag and branchID in chronological order
 }
 
 // NewRepo is a constructor for a repo object, parsing the tags that exist
@@ -142,17 +362,13 @@ func NewRepo(cfg GitRepoConfig) (*GitRepo, error) {
 		cfg.PreReleaseTimestampLayout = datetimeTsLayout
 	}
 
-	gitDirPath, err := generateGitDirPath(cfg.RepoPath)
+	gitDirPath, workTree, err := resolveGitDir(cfg.RepoPath)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := os.Stat(gitDirPath); os.IsNotExist(err) {
-		return nil, err
-	}
-
 	log.Println("Opening repo at", gitDirPath)
-	repo, err := git.Open(gitDirPath)
+	repo, err := openRepository(cfg.Backend, gitDirPath)
 	if err != nil {
 		return nil, err
 	}
@@ -160,7 +376,8 @@ func NewRepo(cfg GitRepoConfig) (*GitRepo, error) {
 	if cfg.Branch == "" {
 		branches, err := repo.Branches()
 		if err != nil {
-			return nil, err
+			// show-ref fails without any local branches, eg: in a detached CI checkout
+			log.Println("error listing branches: ", err)
 		}
 
 		// Locate main or master branch.
@@ -175,6 +392,16 @@ func NewRepo(cfg GitRepoConfig) (*GitRepo, error) {
 				cfg.Branch = "master"
 			}
 		}
+
+		// CI systems often only fetch remote-tracking branches
+		for _, b := range []string{"main", "master"} {
+			if cfg.Branch != "" {
+				break
+			}
+			if _, err := resolveCommitID(repo, remoteBranchRef(b)); err == nil {
+				cfg.Branch = b
+			}
+		}
 		if cfg.Branch == "" {
 			return nil, fmt.Errorf("no main or master branch found")
 		}
@@ -182,12 +409,85 @@ func NewRepo(cfg GitRepoConfig) (*GitRepo, error) {
 
 	r := &GitRepo{
 		repo:                      repo,
+		gitDir:                    gitDirPath,
+		workTree:                  workTree,
 		branch:                    cfg.Branch,
 		preReleaseName:            cfg.PreReleaseName,
 		preReleaseTimestampLayout: cfg.PreReleaseTimestampLayout,
+		preReleaseCounter:         cfg.PreReleaseCounter,
 		buildMetadata:             cfg.BuildMetadata,
-		scheme:                    cfg.Scheme,
 		prefix:                    cfg.Prefix,
+		annotatedTag:              cfg.AnnotatedTag,
+		signTag:                   cfg.SignTag,
+		signingKey:                cfg.SigningKey,
+		signingFormat:             cfg.SigningFormat,
+		pushRemote:                cfg.PushRemote,
+		pushRetries:               cfg.PushRetries,
+		tagPrefix:                 cfg.TagPrefix,
+		paths:                     cfg.Paths,
+		allTags:                   cfg.AllTags,
+		initialDevelopment:        cfg.InitialDevelopment,
+		skipUnreleasable:          cfg.SkipUnreleasable,
+		alwaysTag:                 cfg.AlwaysTag,
+		deepenShallow:             cfg.DeepenShallow,
+	}
+
+	if r.annotatedTag || r.signTag {
+		msg := cfg.TagMessage
+		if msg == "" {
+			msg = defaultTagMessage
+		}
+		if r.tagMessage, err = template.New("tag-message").Parse(msg); err != nil {
+			return nil, fmt.Errorf("error parsing tag message template: %s", err)
+		}
+	}
+
+	r.schemeName = cfg.Scheme
+	if r.scheme, err = lookupScheme(r.schemeName); err != nil {
+		return nil, err
+	}
+	if len(cfg.ConventionalTypes) > 0 {
+		if r.scheme, err = newConventionalScheme(cfg.ConventionalTypes); err != nil {
+			return nil, err
+		}
+	}
+
+	// a remote-tracking branch belongs to the same version line as the local branch
+	if r.maintenanceLine, err = parseMaintenanceLine(cfg.MaintenanceBranches, strings.TrimPrefix(r.branch, "origin/")); err != nil {
+		return nil, err
+	}
+
+	if cfg.InitialVersion != "" {
+		if r.initialVersion, err = version.NewSemver(cfg.InitialVersion); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Bump != "" {
+		if r.forceBump, err = parseBumpLevel(cfg.Bump); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Version != "" {
+		if r.explicitVersion, err = version.NewSemver(cfg.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.retrieveBranchInfo(); err != nil {
+		return nil, err
+	}
+
+	if err := r.checkShallow(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Promote {
+		if err := r.Promote(); err != nil {
+			return nil, err
+		}
+		return r, nil
 	}
 
 	err = r.parseTags()
@@ -211,6 +511,23 @@ func validateConfig(cfg GitRepoConfig) error {
 		return fmt.Errorf("'%s' is not valid SemVer pre-release name", cfg.PreReleaseName)
 	}
 
+	if _, err := lookupScheme(cfg.Scheme); err != nil {
+		return err
+	}
+
+	if _, ok := backends[cfg.Backend]; cfg.Backend != "" && !ok {
+		return fmt.Errorf("unknown backend '%s'; must be one of %v", cfg.Backend, backendNames())
+	}
+
+	if len(cfg.ConventionalTypes) > 0 {
+		if cfg.Scheme != "conventional" {
+			return fmt.Errorf("conventional commit types require the conventional scheme")
+		}
+		if _, err := newConventionalScheme(cfg.ConventionalTypes); err != nil {
+			return err
+		}
+	}
+
 	switch cfg.PreReleaseTimestampLayout {
 	case "", "datetime", "epoch":
 		// nothing -- valid values
@@ -218,31 +535,144 @@ func validateConfig(cfg GitRepoConfig) error {
 		return fmt.Errorf("pre-release-timestamp '%s' is not valid; must be (datetime|epoch)", cfg.PreReleaseTimestampLayout)
 	}
 
+	if cfg.PreReleaseCounter {
+		if cfg.PreReleaseName == "" {
+			return fmt.Errorf("pre-release counter requires a pre-release name")
+		}
+		if cfg.PreReleaseTimestampLayout != "" {
+			return fmt.Errorf("pre-release counter cannot be combined with a pre-release timestamp")
+		}
+	}
+
+	switch cfg.SigningFormat {
+	case "", "openpgp", "x509", "ssh":
+		// nothing -- valid values
+	default:
+		return fmt.Errorf("signing format '%s' is not valid; must be (openpgp|x509|ssh)", cfg.SigningFormat)
+	}
+
+	if cfg.PushRetries < 0 {
+		return fmt.Errorf("push retries must not be negative")
+	}
+
+	if cfg.SigningKey != "" && !cfg.SignTag {
+		return fmt.Errorf("a signing key was provided but tag signing is not enabled")
+	}
+
+	for _, p := range cfg.MaintenanceBranches {
+		if _, err := maintenancePatternRex(p); err != nil {
+			return err
+		}
+	}
+
+	if cfg.TagMessage != "" {
+		if _, err := template.New("tag-message").Parse(cfg.TagMessage); err != nil {
+			return fmt.Errorf("tag message is not a valid template: %s", err)
+		}
+	}
+
+	if cfg.InitialVersion != "" && !validateCoreVersion(cfg.InitialVersion) {
+		return fmt.Errorf("initial version '%s' is not valid; must be MAJOR.MINOR.PATCH, eg: 0.1.0", cfg.InitialVersion)
+	}
+
+	if cfg.Bump != "" {
+		if _, err := parseBumpLevel(cfg.Bump); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Version != "" {
+		if !validateCoreVersion(cfg.Version) {
+			return fmt.Errorf("version '%s' is not valid; must be MAJOR.MINOR.PATCH, eg: 1.2.3", cfg.Version)
+		}
+		if cfg.Bump != "" {
+			return fmt.Errorf("an explicit version cannot be combined with a forced bump")
+		}
+	}
+
 	return nil
 }
 
-func generateGitDirPath(repoPath string) (string, error) {
-	absolutePath, err := filepath.Abs(repoPath)
+// Parse tags on repo, sort them, and store the most recent revision in the repo object. Unless allTags
+// is set only tags reachable from the branch are considered. Only the commit of the tag picked is
+// read, since repositories can have thousands of tags.
+func (r *GitRepo) parseTags() error {
+	log.Println("Parsing repository tags")
+
+	tags, err := r.versionTags(r.allTags)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].version.GreaterThan(tags[j].version)
+	})
+
+	// loop over the tags and find the last reachable non pre-release tag,
+	// because we want to calculate the tag from v1.2.3 not v1.2.4-pre1.`
+	for _, tag := range tags {
+		if len(tag.version.Prerelease()) == 0 {
+			c, err := r.repo.CommitByRevision(tag.revision())
+			if err != nil {
+				return fmt.Errorf("error reading commit '%s':  %s", tag.name, err)
+			}
+
+			r.currentVersion = tag.version
+			r.currentTag = c
+			r.currentTagName = tag.name
+			return nil
+		}
+		log.Printf("skipping pre-release tag version: %s", tag.version.String())
 	}
 
-	return filepath.Join(absolutePath, ".git"), nil
+	// without a version tag the first release is calculated from the whole history
+	if r.initialVersion != nil {
+		log.Printf("no stable version tags found, calculating the initial version from %s", r.initialVersion)
+		r.currentVersion = version.Must(version.NewVersion("0.0.0"))
+		r.currentTag = nil
+		r.currentTagName = ""
+		return nil
+	}
+
+	return fmt.Errorf("no stable (non pre-release) version tags found")
+}
+
+// versionTag is a tag holding a version
+type versionTag struct {
+	tagRef
+	version *version.Version
 }
 
-// Parse tags on repo, sort them, and store the most recent revision in the repo object
-func (r *GitRepo) parseTags() error {
-	log.Println("Parsing repository tags")
+// revision returns the tagged commit id if known, or else the tag name
+func (t versionTag) revision() string {
+	if t.commit != "" {
+		return t.commit
+	}
+	return t.name
+}
 
-	versions := make(map[*version.Version]*git.Commit)
+// versionTags returns the tags with the configured tag prefix that hold a version, sorted by name.
+// Unless all is set only tags reachable from the branch are returned.
+func (r *GitRepo) versionTags(all bool) ([]versionTag, error) {
+	var filter tagFilter
+	if !all {
+		filter.merged = r.branchID
+	}
 
-	tags, err := r.repo.Tags()
+	refs, err := r.repo.TagRefs(filter)
 	if err != nil {
-		return fmt.Errorf("failed to fetch tags: %s", err.Error())
+		return nil, fmt.Errorf("failed to fetch tags: %s", err.Error())
 	}
 
-	for tag, commit := range tags {
-		v, err := maybeVersionFromTag(commit)
+	var versions []versionTag
+	for _, ref := range refs {
+		tag := ref.name
+		if !strings.HasPrefix(tag, r.tagPrefix) {
+			log.Println("skipping tag without prefix: ", tag)
+			continue
+		}
+
+		v, err := maybeVersionFromTag(strings.TrimPrefix(tag, r.tagPrefix))
 		if err != nil {
 			log.Println("skipping non version tag: ", tag)
 			continue
@@ -253,31 +683,9 @@ func (r *GitRepo) parseTags() error {
 			continue
 		}
 
-		c, err := r.repo.CommitByRevision(commit)
-		if err != nil {
-			return fmt.Errorf("error reading commit '%s':  %s", commit, err)
-		}
-		versions[v] = c
-	}
-
-	keys := make([]*version.Version, 0, len(versions))
-	for key := range versions {
-		keys = append(keys, key)
-	}
-	sort.Sort(sort.Reverse(version.Collection(keys)))
-
-	// loop over the tags and find the last reachable non pre-release tag,
-	// because we want to calculate the tag from v1.2.3 not v1.2.4-pre1.`
-	for _, version := range keys {
-		if len(version.Prerelease()) == 0 {
-			r.currentVersion = version
-			r.currentTag = versions[version]
-			return nil
-		}
-		log.Printf("skipping pre-release tag version: %s", version.String())
+		versions = append(versions, versionTag{tagRef: ref, version: v})
 	}
-
-	return fmt.Errorf("no stable (non pre-release) version tags found")
+	return versions, nil
 }
 
 func maybeVersionFromTag(tag string) (*version.Version, error) {
@@ -314,16 +722,41 @@ func (r *GitRepo) LatestVersion() string {
 	return r.newVersion.String()
 }
 
+// retrieveBranchInfo resolves the branch to the commit to tag. A local branch is preferred, then
+// any revision git understands, eg: origin/main, HEAD or a commit id, and finally the branch on
+// origin, since CI systems often check out a detached HEAD with only remote-tracking branches.
 func (r *GitRepo) retrieveBranchInfo() error {
-	id, err := r.repo.BranchCommitID(r.branch)
+	if c, err := r.repo.BranchCommit(r.branch); err == nil {
+		r.branchID = c.ID.String()
+		return nil
+	}
+
+	id, err := resolveCommitID(r.repo, r.branch, remoteBranchRef(r.branch))
 	if err != nil {
-		return fmt.Errorf("error getting head commit: %s ", err.Error())
+		return fmt.Errorf("error getting head commit: no branch or revision '%s' found locally or on origin", r.branch)
 	}
 
 	r.branchID = id
 	return nil
 }
 
+// resolveCommitID returns the id of the commit the first existing revision points to
+func resolveCommitID(repo repository, revs ...string) (string, error) {
+	for _, rev := range revs {
+		c, err := repo.CommitByRevision(rev)
+		if err == nil {
+			log.Printf("Resolved %s to %s", rev, c.ID)
+			return c.ID.String(), nil
+		}
+	}
+	return "", git.ErrRevisionNotExist
+}
+
+// remoteBranchRef returns the ref of the remote-tracking branch of origin for branch
+func remoteBranchRef(branch string) string {
+	return "refs/remotes/origin/" + branch
+}
+
 func preReleaseVersion(v *version.Version, name, tsLayout string) (*version.Version, error) {
 	if len(name) == 0 && len(tsLayout) == 0 {
 		return v, nil
@@ -370,28 +803,103 @@ func preReleaseVersion(v *version.Version, name, tsLayout string) (*version.Vers
 	return version.NewVersion(verStr)
 }
 
+// nextPreReleaseName returns the pre-release name followed by the next counter value for the new
+// version, eg: "rc.3" when v1.2.3-rc.1 and v1.2.3-rc.2 are already tagged. Tags on every branch are
+// considered, so the counter never produces a tag that already exists.
+func (r *GitRepo) nextPreReleaseName() (string, error) {
+	base := r.tagName()
+	tags, err := r.repo.Tags(tagFilter{pattern: fmt.Sprintf("%s-%s.*", base, r.preReleaseName)})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch pre-release tags: %s", err.Error())
+	}
+
+	counter := 0
+	for _, tag := range tags {
+		v, err := maybeVersionFromTag(strings.TrimPrefix(tag, r.tagPrefix))
+		if err != nil || v == nil || !v.Core().Equal(r.newVersion) {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimPrefix(v.Prerelease(), r.preReleaseName+"."))
+		if err != nil {
+			log.Println("skipping pre-release tag without counter: ", tag)
+			continue
+		}
+		if n > counter {
+			counter = n
+		}
+	}
+
+	return fmt.Sprintf("%s.%d", r.preReleaseName, counter+1), nil
+}
+
 // calcVersion looks over commits since the last tag, and will apply the version bump needed. It will patch if no other instruction is found
 // it populates the repo.newVersion with the new calculated version
 func (r *GitRepo) calcVersion() error {
 	r.newVersion = r.currentVersion
-	if err := r.retrieveBranchInfo(); err != nil {
-		return err
+	r.bump = BumpUnspecified
+	r.bumpCommit = nil
+	r.commits = nil
+	r.noRelease = false
+	r.existingTag = ""
+
+	if !r.alwaysTag {
+		tag, v, err := r.branchVersionTag()
+		if err != nil {
+			return err
+		}
+		if tag != "" && tag == r.currentTagName && r.skipUnreleasable && r.forceBump == BumpUnspecified && r.explicitVersion == nil {
+			// no commits since the last version tag
+			log.Printf("commit %s is the last version tag %s", r.branchID, tag)
+			r.noRelease = true
+			return nil
+		}
+
+		satisfied := tag != ""
+		if satisfied && r.explicitVersion != nil {
+			// an explicit version is only satisfied by a tag of that version
+			satisfied = v.Core().Equal(r.explicitVersion)
+		} else if satisfied && r.forceBump != BumpUnspecified {
+			// a forced bump is only satisfied by a tag of at least the bumped version
+			if satisfied, err = r.satisfiesForcedBump(v); err != nil {
+				return err
+			}
+		}
+		if satisfied {
+			log.Printf("commit %s already has version tag %s", r.branchID, tag)
+			r.existingTag = tag
+			r.newVersion = v
+			return nil
+		}
 	}
 
-	startCommit, err := r.repo.BranchCommit(r.branch)
+	startCommit, err := r.repo.CommitByRevision(r.branchID)
 	if err != nil {
 		return err
 	}
 
-	revList := []string{fmt.Sprintf("%s..%s", r.currentTag.ID, startCommit.ID)}
+	revList := []string{startCommit.ID.String()}
+	if r.currentTag != nil {
+		revList = []string{fmt.Sprintf("%s..%s", r.currentTag.ID, startCommit.ID)}
+	}
 
 	l, err := r.repo.RevList(revList)
 	if err != nil {
-		log.Printf("Error loading history for tag '%s': %s ", r.currentVersion, err.Error())
+		return fmt.Errorf("error loading history for tag '%s': %s", r.currentVersion, err)
+	}
+
+	if len(r.paths) > 0 {
+		if l, err = r.filterCommitsByPath(revList, l); err != nil {
+			return err
+		}
 	}
 
 	// r.branchID is newest commit; r.currentTag.ID is oldest
-	log.Printf("Checking commits from %s to %s ", r.branchID, r.currentTag.ID)
+	if r.currentTag != nil {
+		log.Printf("Checking commits from %s to %s ", r.branchID, r.currentTag.ID)
+	} else {
+		log.Printf("Checking all commits up to %s ", r.branchID)
+	}
 
 	// Revlist returns in reverse Crhonological We want chonological. Then check each commit for bump messages
 	for i := len(l) - 1; i >= 0; i-- {
@@ -400,14 +908,48 @@ func (r *GitRepo) calcVersion() error {
 			return fmt.Errorf("commit pointed to nil object. This should not happen.")
 		}
 
-		v, nerr := r.parseCommit(commit)
+		level, v, nerr := r.parseCommit(commit)
 		if nerr != nil {
 			log.Fatal(nerr)
 		}
 
+		r.commits = append(r.commits, inspectedCommit{Commit: commit, bump: level})
+
 		if v != nil && v.GreaterThan(r.newVersion) {
 			r.newVersion = v
+			r.bump = level
+			r.bumpCommit = commit
+		}
+	}
+
+	if r.skipUnreleasable && r.currentTag != nil && r.forceBump == BumpUnspecified && r.explicitVersion == nil && !r.releasable() {
+		log.Printf("no releasable commits since %s", r.currentTagName)
+		r.noRelease = true
+		return nil
+	}
+
+	// a component without changes keeps its version
+	if len(r.paths) > 0 && len(r.commits) == 0 && r.forceBump == BumpUnspecified && r.explicitVersion == nil {
+		log.Printf("no commits touching %s since %s", strings.Join(r.paths, ", "), r.currentTagName)
+		r.noRelease = true
+		return nil
+	}
+
+	// a forced bump or explicit version overrides the commits
+	switch {
+	case r.explicitVersion != nil:
+		if !r.explicitVersion.GreaterThan(r.currentVersion) {
+			return fmt.Errorf("version %s must be greater than the current version %s", r.explicitVersion, r.currentVersion)
+		}
+		r.newVersion = r.explicitVersion
+		r.bump = bumpBetween(r.currentVersion, r.newVersion)
+		r.bumpCommit = nil
+	case r.forceBump != BumpUnspecified:
+		if r.newVersion, err = r.forceBump.bumper().bump(r.currentVersion); err != nil {
+			return err
 		}
+		r.bump = r.forceBump
+		r.bumpCommit = nil
 	}
 
 	// if there is no movement on the version from commits, bump patch
@@ -415,11 +957,35 @@ func (r *GitRepo) calcVersion() error {
 		if r.newVersion, err = patchBumper.bump(r.currentVersion); err != nil {
 			return err
 		}
+		r.bump = BumpPatch
+	}
+
+	// the first release is at least the initial version
+	if r.currentTag == nil && r.explicitVersion == nil && r.newVersion.LessThan(r.initialVersion) {
+		r.newVersion = r.initialVersion
+		r.bump = BumpUnspecified
+		r.bumpCommit = nil
+	}
+
+	// maintenance branches must stay within the version line declared by their name
+	if r.maintenanceLine != nil && !r.maintenanceLine.contains(r.newVersion) {
+		if r.bumpCommit != nil {
+			return fmt.Errorf("commit %s requests a %s bump to %s, which is outside of the %s line of maintenance branch %s",
+				r.bumpCommit.ID, r.bump, r.newVersion, r.maintenanceLine, r.branch)
+		}
+		return fmt.Errorf("version %s is outside of the %s line of maintenance branch %s", r.newVersion, r.maintenanceLine, r.branch)
 	}
 
 	// append pre-release-name and/or pre-release-timestamp to the version
 	if len(r.preReleaseName) > 0 || len(r.preReleaseTimestampLayout) > 0 {
-		if r.newVersion, err = preReleaseVersion(r.newVersion, r.preReleaseName, r.preReleaseTimestampLayout); err != nil {
+		name := r.preReleaseName
+		if r.preReleaseCounter {
+			if name, err = r.nextPreReleaseName(); err != nil {
+				return err
+			}
+		}
+
+		if r.newVersion, err = preReleaseVersion(r.newVersion, name, r.preReleaseTimestampLayout); err != nil {
 			return err
 		}
 	}
@@ -436,43 +1002,209 @@ func (r *GitRepo) calcVersion() error {
 
 // AutoTag applies the new version tag thats calculated
 func (r *GitRepo) AutoTag() error {
-	return r.tagNewVersion()
+	if r.noRelease {
+		return ErrNoRelease
+	}
+
+	if r.existingTag == "" {
+		if err := r.tagNewVersion(); err != nil {
+			return err
+		}
+	}
+
+	if r.pushRemote != "" {
+		return r.pushNewVersion()
+	}
+	return nil
 }
 
-func (r *GitRepo) tagNewVersion() error {
-	// TODO:(jnelson) These should be configurable? Mon Sep 14 12:02:52 2015
-	tagName := fmt.Sprintf("v%s", r.newVersion.String())
+// tagName returns the name of the tag for the new version
+func (r *GitRepo) tagName() string {
+	if r.existingTag != "" {
+		return r.existingTag
+	}
 	if !r.prefix {
-		tagName = r.newVersion.String()
+		return r.tagPrefix + r.newVersion.String()
+	}
+	return fmt.Sprintf("%sv%s", r.tagPrefix, r.newVersion.String())
+}
+
+// filterCommitsByPath returns the commits from l that touch at least one of the configured paths,
+// preserving their order
+func (r *GitRepo) filterCommitsByPath(revList []string, l []*git.Commit) ([]*git.Commit, error) {
+	out, err := git.NewCommand("rev-list").AddArgs(revList...).AddArgs("--").AddArgs(r.paths...).RunInDir(r.gitDir)
+	if err != nil {
+		return nil, fmt.Errorf("error loading history for paths %v: %s", r.paths, err)
+	}
+
+	touched := make(map[string]bool)
+	for _, id := range strings.Fields(string(out)) {
+		touched[id] = true
+	}
+
+	filtered := make([]*git.Commit, 0, len(touched))
+	for _, c := range l {
+		if c != nil && touched[c.ID.String()] {
+			filtered = append(filtered, c)
+		} else if c != nil {
+			log.Printf("skipping %s: no changes in %v", c.ID, r.paths)
+		}
+	}
+	return filtered, nil
+}
+
+func (r *GitRepo) tagNewVersion() error {
+	tagName := r.tagName()
+
+	if r.signTag {
+		return r.createSignedTag(tagName)
+	}
+
+	var opts git.CreateTagOptions
+	if r.annotatedTag {
+		msg, err := r.tagMessageFor(tagName)
+		if err != nil {
+			return err
+		}
+		opts.Annotated = true
+		opts.Message = msg
 	}
 
 	log.Println("Writing Tag", tagName)
-	err := r.repo.CreateTag(tagName, r.branchID)
+	err := r.repo.CreateTag(tagName, r.branchID, opts)
 	if err != nil {
 		return fmt.Errorf("error creating tag: %s", err.Error())
 	}
 	return nil
 }
 
+// tagMessageFor renders the annotated tag message template for the given tag name
+func (r *GitRepo) tagMessageFor(tagName string) (string, error) {
+	data := TagMessageData{
+		Tag:             tagName,
+		Version:         r.newVersion.String(),
+		PreviousVersion: r.currentVersion.String(),
+	}
+	if r.bump != BumpUnspecified {
+		data.Bump = r.bump.String()
+	}
+	for _, c := range r.commits {
+		data.Commits = append(data.Commits, c.Commit)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := r.tagMessage.Execute(buf, data); err != nil {
+		return "", fmt.Errorf("error rendering tag message: %s", err)
+	}
+	return buf.String(), nil
+}
+
+// branchVersionTag returns the highest version tag on branchID that AutoTag could have created
+// with the current configuration, or an empty string if there is none
+func (r *GitRepo) branchVersionTag() (string, *version.Version, error) {
+	tags, err := r.repo.Tags(tagFilter{pointsAt: r.branchID})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to fetch tags: %s", err.Error())
+	}
+
+	var (
+		name    string
+		highest *version.Version
+	)
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, r.tagPrefix) {
+			continue
+		}
+		trimmed := strings.TrimPrefix(tag, r.tagPrefix)
+		if strings.HasPrefix(trimmed, "v") != r.prefix {
+			continue
+		}
+
+		v, err := maybeVersionFromTag(trimmed)
+		if err != nil || v == nil || !r.matchesPreRelease(v) {
+			continue
+		}
+
+		if highest == nil || v.GreaterThan(highest) {
+			name, highest = tag, v
+		}
+	}
+	return name, highest, nil
+}
+
+// satisfiesForcedBump reports whether v, the version of a tag on branchID, is at least the forced
+// bump applied to the version released before it, ie: the highest stable version tag reachable from
+// the branch that isn't on branchID itself. Without an earlier tag, only a first release from the
+// initial version can satisfy it.
+func (r *GitRepo) satisfiesForcedBump(v *version.Version) (bool, error) {
+	tags, err := r.versionTags(r.allTags)
+	if err != nil {
+		return false, err
+	}
+
+	var previous *version.Version
+	for _, tag := range tags {
+		if tag.commit == r.branchID || tag.version.Prerelease() != "" {
+			continue
+		}
+		if previous == nil || tag.version.GreaterThan(previous) {
+			previous = tag.version
+		}
+	}
+	if previous == nil {
+		if r.initialVersion == nil {
+			return false, nil
+		}
+		previous = version.Must(version.NewVersion("0.0.0"))
+	}
+
+	bumped, err := r.forceBump.bumper().bump(previous)
+	if err != nil {
+		return false, err
+	}
+	return !v.Core().LessThan(bumped), nil
+}
+
+// matchesPreRelease reports whether v is the kind of version AutoTag creates: a pre-release with
+// the configured name, or a stable version if no pre-release is configured
+func (r *GitRepo) matchesPreRelease(v *version.Version) bool {
+	pre := v.Prerelease()
+	if r.preReleaseName == "" && r.preReleaseTimestampLayout == "" {
+		return pre == ""
+	}
+	if r.preReleaseName == "" {
+		return pre != ""
+	}
+	return pre == r.preReleaseName || strings.HasPrefix(pre, r.preReleaseName+".")
+}
+
+// releasable reports whether any of the inspected commits warrants a release
+func (r *GitRepo) releasable() bool {
+	for _, c := range r.commits {
+		if c.bump != BumpNone {
+			return true
+		}
+	}
+	return false
+}
+
 // parseCommit looks at HEAD commit see if we want to increment major/minor/patch
-func (r *GitRepo) parseCommit(commit *git.Commit) (*version.Version, error) {
-	var b bumper
+func (r *GitRepo) parseCommit(commit *git.Commit) (BumpLevel, *version.Version, error) {
 	msg := commit.Message
 	log.Printf("Parsing %s: %s\n", commit.ID, msg)
 
-	switch r.scheme {
-	case "conventional":
-		b = parseConventionalCommit(msg)
-	case "", "autotag":
-		b = parseAutotagCommit(msg)
+	level := r.scheme.Bump(msg)
+	if r.initialDevelopment && r.currentVersion.Segments()[0] == 0 {
+		level = level.initialDevelopment()
 	}
 
 	// fallback to patch bump if no matches from the scheme parsers
-	if b != nil {
-		return b.bump(r.currentVersion)
+	if b := level.bumper(); b != nil {
+		v, err := b.bump(r.currentVersion)
+		return level, v, err
 	}
 
-	return nil, nil
+	return level, nil, nil
 }
 
 // parseAutotagCommit implements the autotag (default) commit scheme.
@@ -481,49 +1213,103 @@ func (r *GitRepo) parseCommit(commit *git.Commit) (*version.Version, error) {
 //   - [minor] or #minor: minor version bump
 //   - [patch] or #patch: patch version bump
 //
-// If no action is present nil is returned and the caller must decide what action to take.
-func parseAutotagCommit(msg string) bumper {
+// The rule that matched is returned along with the bump level. If no action is present
+// BumpUnspecified is returned and the caller must decide what action to take.
+func parseAutotagCommit(msg string) (BumpLevel, string) {
 	if majorRex.MatchString(msg) {
 		log.Println("major bump")
-		return majorBumper
+		return BumpMajor, "[major] or #major"
 	}
 
 	if minorRex.MatchString(msg) {
 		log.Println("minor bump")
-		return minorBumper
+		return BumpMinor, "[minor] or #minor"
 	}
 
 	if patchRex.MatchString(msg) {
 		log.Println("patch bump")
-		return patchBumper
+		return BumpPatch, "[patch] or #patch"
 	}
 
-	return nil
+	return BumpUnspecified, ""
+}
+
+// defaultConventionalTypes is the bump requested by each commit type of the conventional scheme,
+// unless configured otherwise with GitRepoConfig.ConventionalTypes
+var defaultConventionalTypes = map[string]BumpLevel{
+	"feat": BumpMinor,
+}
+
+// conventionalScheme implements the Conventional Commit scheme with the bump requested by each
+// commit type.
+type conventionalScheme map[string]BumpLevel
+
+// Bump returns the bump level requested by the commit message.
+func (s conventionalScheme) Bump(msg string) BumpLevel {
+	level, _ := parseConventionalCommit(msg, s)
+	return level
+}
+
+// Rule returns the bump level requested by the commit message and the rule that matched.
+func (s conventionalScheme) Rule(msg string) (BumpLevel, string) {
+	return parseConventionalCommit(msg, s)
+}
+
+// newConventionalScheme returns the conventional scheme with the default bump of commit types
+// overridden by types, which maps a commit type to "major", "minor", "patch" or "none"
+func newConventionalScheme(types map[string]string) (conventionalScheme, error) {
+	s := make(conventionalScheme, len(defaultConventionalTypes)+len(types))
+	for typ, level := range defaultConventionalTypes {
+		s[typ] = level
+	}
+
+	for typ, name := range types {
+		level := BumpNone
+		if name != level.String() {
+			var err error
+			if level, err = parseBumpLevel(name); err != nil {
+				return nil, fmt.Errorf("bump '%s' of conventional commit type '%s' is not valid; must be (major|minor|patch|none)", name, typ)
+			}
+		}
+		s[typ] = level
+	}
+	return s, nil
 }
 
 // parseConventionalCommit implements the Conventional Commit scheme. Given a commit message
-// it will return the correct version bumper. In the case of non-confirming conventional commit
-// it will return nil and the caller will decide what action to take.
+// it will return the correct bump level and the rule that matched. The bump of commit types is
+// looked up in types. In the case of non-confirming conventional commit, or a type that isn't in
+// types, it will return BumpUnspecified and the caller will decide what action to take.
 // https://www.conventionalcommits.org/en/v1.0.0/#summary
-func parseConventionalCommit(msg string) bumper {
+func parseConventionalCommit(msg string, types map[string]BumpLevel) (BumpLevel, string) {
 	matches := findNamedMatches(conventionalCommitRex, msg)
 
-	// If the commit contains a footer with 'BREAKING CHANGE:' it is always a major bump
-	if strings.Contains(msg, "\nBREAKING CHANGE:") {
-		return majorBumper
+	// a breaking change is always a major bump
+	if rule := conventionalBreakingChange(msg, matches); rule != "" {
+		return BumpMajor, rule
 	}
 
-	// if the type/scope in the header includes a trailing '!' this is a breaking change
-	if breaking, ok := matches["breaking"]; ok && breaking == "!" {
-		return majorBumper
+	// otherwise the type in the header decides, eg: 'feat' is a minor change
+	if level, ok := types[matches["type"]]; ok {
+		return level, "type " + matches["type"]
 	}
 
-	// if the type in the header is 'feat' it is a minor change
-	if typ, ok := matches["type"]; ok && typ == "feat" {
-		return minorBumper
+	return BumpUnspecified, ""
+}
+
+// conventionalBreakingChange returns the rule marking a conventional commit as a breaking change, or
+// an empty string if it isn't one. matches are the conventionalCommitRex matches of its header.
+func conventionalBreakingChange(msg string, matches map[string]string) string {
+	// If the commit contains a footer with 'BREAKING CHANGE:' it is a breaking change
+	if strings.Contains(msg, "\nBREAKING CHANGE:") {
+		return "BREAKING CHANGE: footer"
 	}
 
-	return nil
+	// if the type/scope in the header includes a trailing '!' this is a breaking change
+	if matches["breaking"] == "!" {
+		return "! after type/scope"
+	}
+	return ""
 }
 
 // MajorBump will bump the version one major rev 1.0.0 -> 2.0.0
@@ -555,6 +1341,12 @@ func findNamedMatches(regex *regexp.Regexp, str string) map[string]string {
 	return results
 }
 
+// validateCoreVersion returns true if v is a plain MAJOR.MINOR.PATCH version, eg: 1.2.3
+func validateCoreVersion(v string) bool {
+	ver, err := version.NewSemver(v)
+	return err == nil && ver.Original() == ver.Core().String()
+}
+
 // validateSemVerBuildMetadata validates SemVer build metadata strings according to
 // https://semver.org/#spec-item-10
 func validateSemVerBuildMetadata(meta string) bool {
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/pantheon-systems/autotag"
+)
+
+// ChangelogOptions holds the CLI args of the changelog command
+type ChangelogOptions struct {
+	File string `short:"f" long:"file" description:"Prepend the changelog to this file in Keep a Changelog format (eg: CHANGELOG.md) instead of printing it"`
+}
+
+var changelogOpts ChangelogOptions
+
+func addChangelogCommand(p *flags.Parser) {
+	_, err := p.AddCommand("changelog",
+		"Generate a changelog for the next version",
+		"Renders the commits since the last version tag as Markdown, grouped by bump level, or by commit type and scope with --scheme=conventional.",
+		&changelogOpts)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runChangelog prints the changelog of the next version, or prepends it to the changelog file
+func runChangelog(r *autotag.GitRepo) error {
+	section := r.Changelog()
+	if changelogOpts.File == "" {
+		fmt.Print(section)
+		return nil
+	}
+
+	existing, err := os.ReadFile(changelogOpts.File)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return os.WriteFile(changelogOpts.File, []byte(autotag.PrependChangelog(string(existing), section)), 0o644)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/pantheon-systems/autotag"
+)
+
+// ConfigOptions holds the CLI args of the config command
+type ConfigOptions struct {
+	Validate struct{} `command:"validate" description:"Validate the repository config file and options" long-description:"Reads the config file at the root of the repo, applies the command line options and checks the result for errors without calculating a version."`
+}
+
+var configOpts ConfigOptions
+
+func addConfigCommand(p *flags.Parser) {
+	_, err := p.AddCommand("config",
+		"Inspect the autotag configuration",
+		"Commands working with the repository config file (.autotag.yml, .autotag.yaml or .autotag.toml).",
+		&configOpts)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// optionOverrides copies the settings of each CLI option to a library configuration
+var optionOverrides = map[string]func(dst, src *autotag.GitRepoConfig){
+	"branch":                func(dst, src *autotag.GitRepoConfig) { dst.Branch = src.Branch },
+	"pre-release-name":      func(dst, src *autotag.GitRepoConfig) { dst.PreReleaseName = src.PreReleaseName },
+	"pre-release-timestamp": func(dst, src *autotag.GitRepoConfig) { dst.PreReleaseTimestampLayout = src.PreReleaseTimestampLayout },
+	"pre-release-counter":   func(dst, src *autotag.GitRepoConfig) { dst.PreReleaseCounter = src.PreReleaseCounter },
+	"build-metadata":        func(dst, src *autotag.GitRepoConfig) { dst.BuildMetadata = src.BuildMetadata },
+	"scheme":                func(dst, src *autotag.GitRepoConfig) { dst.Scheme = src.Scheme },
+	"empty-version-prefix":  func(dst, src *autotag.GitRepoConfig) { dst.Prefix = src.Prefix },
+	"annotate":              func(dst, src *autotag.GitRepoConfig) { dst.AnnotatedTag = src.AnnotatedTag },
+	"tag-message":           func(dst, src *autotag.GitRepoConfig) { dst.TagMessage = src.TagMessage },
+	"sign":                  func(dst, src *autotag.GitRepoConfig) { dst.SignTag = src.SignTag },
+	"signing-key": func(dst, src *autotag.GitRepoConfig) {
+		dst.SigningKey = src.SigningKey
+		dst.SignTag = true
+	},
+	"signing-format":      func(dst, src *autotag.GitRepoConfig) { dst.SigningFormat = src.SigningFormat },
+	"push":                func(dst, src *autotag.GitRepoConfig) { dst.PushRemote = src.PushRemote },
+	"push-retries":        func(dst, src *autotag.GitRepoConfig) { dst.PushRetries = src.PushRetries },
+	"tag-prefix":          func(dst, src *autotag.GitRepoConfig) { dst.TagPrefix = src.TagPrefix },
+	"path":                func(dst, src *autotag.GitRepoConfig) { dst.Paths = src.Paths },
+	"all-tags":            func(dst, src *autotag.GitRepoConfig) { dst.AllTags = src.AllTags },
+	"maintenance-branch":  func(dst, src *autotag.GitRepoConfig) { dst.MaintenanceBranches = src.MaintenanceBranches },
+	"initial-version":     func(dst, src *autotag.GitRepoConfig) { dst.InitialVersion = src.InitialVersion },
+	"bump":                func(dst, src *autotag.GitRepoConfig) { dst.Bump = src.Bump },
+	"set-version":         func(dst, src *autotag.GitRepoConfig) { dst.Version = src.Version },
+	"initial-development": func(dst, src *autotag.GitRepoConfig) { dst.InitialDevelopment = src.InitialDevelopment },
+	"conventional-type":   func(dst, src *autotag.GitRepoConfig) { dst.ConventionalTypes = src.ConventionalTypes },
+	"skip-unreleasable":   func(dst, src *autotag.GitRepoConfig) { dst.SkipUnreleasable = src.SkipUnreleasable },
+	"always-tag":          func(dst, src *autotag.GitRepoConfig) { dst.AlwaysTag = src.AlwaysTag },
+	"deepen-shallow":      func(dst, src *autotag.GitRepoConfig) { dst.DeepenShallow = src.DeepenShallow },
+	"backend":             func(dst, src *autotag.GitRepoConfig) { dst.Backend = src.Backend },
+}
+
+// loadConfig returns the library configuration for the options parsed by p into o and the path of
+// the config file it was read from, if any. Options given on the command line or in their
+// environment variables take precedence over the config file, which takes precedence over the
+// defaults of the options.
+func loadConfig(p *flags.Parser, o Options) (autotag.GitRepoConfig, string, error) {
+	flagCfg := repoConfig(o)
+
+	cfg := flagCfg
+	path, err := autotag.LoadConfigFile(o.RepoPath, &cfg)
+	if err != nil {
+		return cfg, "", err
+	}
+
+	for name, override := range optionOverrides {
+		if optionSet(p, name) {
+			override(&cfg, &flagCfg)
+		}
+	}
+	return cfg, path, nil
+}
+
+// optionSet reports whether the option with the given long name was given on the command line or
+// in a non-empty environment variable
+func optionSet(p *flags.Parser, name string) bool {
+	opt := p.FindOptionByLongName(name)
+	if opt == nil || !opt.IsSet() {
+		return false
+	}
+	if !opt.IsSetDefault() {
+		return true
+	}
+
+	// go-flags treats values from the environment as defaults
+	return os.Getenv(opt.EnvKeyWithNamespace()) != ""
+}
+
+// runConfigValidate checks the configuration without opening the repository
+func runConfigValidate(cfg autotag.GitRepoConfig, path string) error {
+	if err := autotag.ValidateConfig(cfg); err != nil {
+		return err
+	}
+
+	if path == "" {
+		fmt.Println("no config file found; options are valid")
+		return nil
+	}
+	fmt.Printf("%s is valid\n", path)
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert"
+	"github.com/jessevdk/go-flags"
+)
+
+func TestLoadConfig(t *testing.T) {
+	const file = "branch: develop\nscheme: conventional\npush-retries: 0\n"
+
+	tests := []struct {
+		name                string
+		file                string
+		env                 map[string]string
+		args                []string
+		expectedBranch      string
+		expectedScheme      string
+		expectedPushRetries int
+	}{
+		{
+			name:                "defaults",
+			expectedScheme:      "autotag",
+			expectedPushRetries: 3,
+		},
+		{
+			name:                "config file over defaults",
+			file:                file,
+			expectedBranch:      "develop",
+			expectedScheme:      "conventional",
+			expectedPushRetries: 0,
+		},
+		{
+			name:                "environment over config file",
+			file:                file,
+			env:                 map[string]string{"AUTOTAG_SCHEME": "autotag", "AUTOTAG_PUSH_RETRIES": "5"},
+			expectedBranch:      "develop",
+			expectedScheme:      "autotag",
+			expectedPushRetries: 5,
+		},
+		{
+			name:                "command line over environment",
+			file:                file,
+			env:                 map[string]string{"AUTOTAG_SCHEME": "autotag", "AUTOTAG_BRANCH": "release"},
+			args:                []string{"--scheme", "conventional", "--branch", "main"},
+			expectedBranch:      "main",
+			expectedScheme:      "conventional",
+			expectedPushRetries: 0,
+		},
+		{
+			name:                "empty environment variable",
+			file:                file,
+			env:                 map[string]string{"AUTOTAG_BRANCH": ""},
+			expectedBranch:      "develop",
+			expectedScheme:      "conventional",
+			expectedPushRetries: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tc.file != "" {
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, ".autotag.yml"), []byte(tc.file), 0o644))
+			}
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			var o Options
+			p := flags.NewParser(&o, flags.Default)
+			_, err := p.ParseArgs(append([]string{"--repo", dir}, tc.args...))
+			assert.NoError(t, err)
+
+			cfg, _, err := loadConfig(p, o)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedBranch, cfg.Branch)
+			assert.Equal(t, tc.expectedScheme, cfg.Scheme)
+			assert.Equal(t, tc.expectedPushRetries, cfg.PushRetries)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/pantheon-systems/autotag"
+)
+
+// ExplainOptions holds the CLI args of the explain command
+type ExplainOptions struct{}
+
+var explainOpts ExplainOptions
+
+func addExplainCommand(p *flags.Parser) {
+	_, err := p.AddCommand("explain",
+		"Explain why the next version was chosen",
+		"Prints each commit since the last version tag, the scheme rule it matched and the version it alone would produce. The commit that decided the next version is marked with '*'.",
+		&explainOpts)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runExplain prints a table describing how each commit affected the next version
+func runExplain(r *autotag.GitRepo, cfg autotag.GitRepoConfig) error {
+	explanations, err := r.Explain()
+	if err != nil {
+		return err
+	}
+
+	rel := r.Release()
+	bump := rel.Bump
+	if bump == "" {
+		bump = "no"
+	}
+	previous := rel.PreviousTag
+	if previous == "" {
+		previous = "(no tag)"
+	}
+	fmt.Printf("%s -> %s (%s bump)\n\n", previous, rel.Tag, bump)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\tCOMMIT\tBUMP\tRULE\tVERSION\tSUBJECT")
+
+	decided := false
+	for _, e := range explanations {
+		mark := ""
+		if e.Decisive {
+			mark = "*"
+			decided = true
+		}
+
+		rule := e.Rule
+		if rule == "" {
+			rule = "-"
+		}
+		fmt.Fprintf(w, "%s\t%.7s\t%s\t%s\t%s\t%s\n", mark, e.ID, e.Bump, rule, e.Version, e.Subject)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	switch {
+	case rel.Existing:
+		fmt.Printf("\ncommit %.7s already has version tag %s, so no new tag is needed\n", rel.Commit, rel.Tag)
+	case rel.NoRelease:
+		fmt.Println("\nno commit warrants a release, so no release is needed")
+	case cfg.Version != "":
+		fmt.Printf("\nthe version was set to %s, ignoring the commits\n", cfg.Version)
+	case cfg.Bump != "":
+		fmt.Printf("\na %s bump was forced, ignoring the commits\n", cfg.Bump)
+	case decided:
+		fmt.Println("\n* decided the next version")
+	case len(explanations) > 0:
+		fmt.Println("\nno commit requested a bump, so a patch bump was applied")
+	default:
+		fmt.Println("no commits since the last version tag, so a patch bump was applied")
+	}
+	return nil
+}
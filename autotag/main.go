@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,62 +12,172 @@ import (
 	"github.com/pantheon-systems/autotag"
 )
 
-// Options holds the CLI args
+// Options holds the CLI args. Each option can also be set with the AUTOTAG_* environment variable
+// in its env tag, which takes precedence over the config file but not over the command line.
 type Options struct {
-	JustVersion         bool   `short:"n" description:"Just output the next version, don't autotag"`
-	Verbose             bool   `short:"v" description:"Enable verbose logging"`
-	Branch              string `short:"b" long:"branch" description:"Git branch to scan (defaults to main, then master)" default:""`
-	RepoPath            string `short:"r" long:"repo" description:"Path to the repo" default:"./" `
-	PreReleaseName      string `short:"p" long:"pre-release-name" description:"create a pre-release tag"`
-	PreReleaseTimestamp string `short:"T" long:"pre-release-timestamp" description:"create a pre-release tag and append a timestamp (can be: datetime|epoch)"`
-	BuildMetadata       string `short:"m" long:"build-metadata" description:"optional SemVer build metadata to append to the version with '+' character"`
-	Scheme              string `short:"s" long:"scheme" description:"The commit message scheme to use (can be: autotag|conventional)" default:"autotag"`
-	NoVersionPrefix     bool   `short:"e" long:"empty-version-prefix" description:"Do not prepend v to version tag"`
+	JustVersion         bool              `short:"n" description:"Just output the next version, don't autotag" env:"AUTOTAG_JUST_VERSION"`
+	Promote             bool              `long:"promote" description:"Tag the stable version of the newest pre-release tag on its commit (eg: v1.2.3 from v1.2.3-rc.2)" env:"AUTOTAG_PROMOTE"`
+	Verbose             bool              `short:"v" description:"Enable verbose logging" env:"AUTOTAG_VERBOSE"`
+	Output              string            `short:"o" long:"output" description:"Output format" choice:"text" choice:"json" default:"text" env:"AUTOTAG_OUTPUT"`
+	Branch              string            `short:"b" long:"branch" description:"Git branch to scan (defaults to main, then master)" default:"" env:"AUTOTAG_BRANCH"`
+	RepoPath            string            `short:"r" long:"repo" description:"Path to the repo, whose root may contain a .autotag.yml or .autotag.toml config file" default:"./" env:"AUTOTAG_REPO"`
+	PreReleaseName      string            `short:"p" long:"pre-release-name" description:"create a pre-release tag" env:"AUTOTAG_PRE_RELEASE_NAME"`
+	PreReleaseTimestamp string            `short:"T" long:"pre-release-timestamp" description:"create a pre-release tag and append a timestamp (can be: datetime|epoch)" env:"AUTOTAG_PRE_RELEASE_TIMESTAMP"`
+	PreReleaseCounter   bool              `short:"c" long:"pre-release-counter" description:"append an incrementing counter to the pre-release name (eg: rc.1, rc.2)" env:"AUTOTAG_PRE_RELEASE_COUNTER"`
+	BuildMetadata       string            `short:"m" long:"build-metadata" description:"optional SemVer build metadata to append to the version with '+' character" env:"AUTOTAG_BUILD_METADATA"`
+	Scheme              string            `short:"s" long:"scheme" description:"The commit message scheme to use (built-in: autotag|conventional)" default:"autotag" env:"AUTOTAG_SCHEME"`
+	NoVersionPrefix     bool              `short:"e" long:"empty-version-prefix" description:"Do not prepend v to version tag" env:"AUTOTAG_EMPTY_VERSION_PREFIX"`
+	AnnotatedTag        bool              `short:"a" long:"annotate" description:"Create an annotated tag instead of a lightweight tag" env:"AUTOTAG_ANNOTATE"`
+	TagMessage          string            `long:"tag-message" description:"Go text/template for the annotated tag message (fields: .Tag .Version .PreviousVersion .Bump .Commits)" env:"AUTOTAG_TAG_MESSAGE"`
+	Sign                bool              `short:"S" long:"sign" description:"Create a signed tag using git's signing configuration (gpg.format, user.signingKey)" env:"AUTOTAG_SIGN"`
+	SigningKey          string            `short:"u" long:"signing-key" description:"Key to sign the tag with: a GPG key ID or the path to an SSH key (implies --sign)" env:"AUTOTAG_SIGNING_KEY"`
+	SigningFormat       string            `long:"signing-format" description:"Override git's gpg.format config (can be: openpgp|x509|ssh)" env:"AUTOTAG_SIGNING_FORMAT"`
+	Push                string            `long:"push" description:"Push the new tag to a remote (defaults to origin)" optional:"yes" optional-value:"origin" env:"AUTOTAG_PUSH"`
+	PushRetries         int               `long:"push-retries" description:"Number of times to recalculate the version and retry when the remote has a conflicting tag" default:"3" env:"AUTOTAG_PUSH_RETRIES"`
+	TagPrefix           string            `long:"tag-prefix" description:"Only consider tags with this prefix and create the new tag with it (eg: services/api/)" env:"AUTOTAG_TAG_PREFIX"`
+	Paths               []string          `long:"path" description:"Only consider commits touching this path (can be repeated)" env:"AUTOTAG_PATHS" env-delim:","`
+	AllTags             bool              `long:"all-tags" description:"Consider version tags on all branches, not only those reachable from the scanned branch" env:"AUTOTAG_ALL_TAGS"`
+	MaintenanceBranches []string          `long:"maintenance-branch" description:"Branch pattern declaring a version line that may not be left, eg: release/{major}.{minor} (can be repeated)" env:"AUTOTAG_MAINTENANCE_BRANCHES" env-delim:","`
+	InitialVersion      string            `long:"initial-version" description:"Version of the first release when the repo has no version tags, calculated from the whole history (eg: 0.1.0)" env:"AUTOTAG_INITIAL_VERSION"`
+	Bump                string            `long:"bump" description:"Force the version bump, ignoring commit messages" choice:"major" choice:"minor" choice:"patch" env:"AUTOTAG_BUMP"`
+	SetVersion          string            `long:"set-version" description:"Tag this version instead of calculating it; must be greater than the current version (eg: 2.0.0)" env:"AUTOTAG_SET_VERSION"`
+	InitialDevelopment  bool              `long:"initial-development" description:"On 0.y.z versions bump minor instead of major and patch instead of minor; use --bump=major to release 1.0.0" env:"AUTOTAG_INITIAL_DEVELOPMENT"`
+	ConventionalTypes   map[string]string `long:"conventional-type" description:"Bump for a conventional commit type as type:level, level can be: major|minor|patch|none (eg: perf:minor, can be repeated)" env:"AUTOTAG_CONVENTIONAL_TYPES" env-delim:","`
+	SkipUnreleasable    bool              `long:"skip-unreleasable" description:"Don't tag when there are no commits since the last version tag or none warrant a release, and exit with status 3" env:"AUTOTAG_SKIP_UNRELEASABLE"`
+	AlwaysTag           bool              `long:"always-tag" description:"Create a new tag even if the commit already has a version tag, instead of returning that tag" env:"AUTOTAG_ALWAYS_TAG"`
+	Backend             string            `long:"backend" description:"Git implementation to use: git (the CLI) or go-git (pure Go; pushing, signing, --path and --deepen-shallow still run git)" choice:"git" choice:"go-git" default:"git" env:"AUTOTAG_BACKEND"`
+	DeepenShallow       bool              `long:"deepen-shallow" description:"In a shallow clone fetch tags and deepen the history from origin until a version tag is reachable, instead of failing" env:"AUTOTAG_DEEPEN_SHALLOW"`
 }
 
-var opts Options
+// exitNoRelease is the exit status when --skip-unreleasable finds no commits warranting a release
+const exitNoRelease = 3
+
+var (
+	opts Options
+
+	parser = flags.NewParser(&opts, flags.Default)
+)
 
 func init() {
-	_, err := flags.Parse(&opts)
-	if err != nil {
+	parser.SubcommandsOptional = true
+	addChangelogCommand(parser)
+	addExplainCommand(parser)
+	addConfigCommand(parser)
+}
+
+func main() {
+	if _, err := parser.Parse(); err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
-}
 
-func main() {
 	log.SetOutput(io.Discard)
 	if opts.Verbose {
 		log.SetOutput(os.Stderr)
 	}
 
-	r, err := autotag.NewRepo(autotag.GitRepoConfig{
-		RepoPath:                  opts.RepoPath,
-		Branch:                    opts.Branch,
-		PreReleaseName:            opts.PreReleaseName,
-		PreReleaseTimestampLayout: opts.PreReleaseTimestamp,
-		BuildMetadata:             opts.BuildMetadata,
-		Scheme:                    opts.Scheme,
-		Prefix:                    !opts.NoVersionPrefix,
-	})
+	cfg, cfgFile, err := loadConfig(parser, opts)
+	if err != nil {
+		log.SetOutput(os.Stderr)
+		log.Println("Error loading config: " + err.Error())
+		os.Exit(1)
+	}
+	if cfgFile != "" {
+		log.Println("Using config file", cfgFile)
+	}
+
+	if parser.Active != nil && parser.Active.Name == "config" {
+		if err := runConfigValidate(cfg, cfgFile); err != nil {
+			log.SetOutput(os.Stderr)
+			log.Println("Invalid config: " + err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	r, err := autotag.NewRepo(cfg)
 	if err != nil {
 		log.SetOutput(os.Stderr)
 		log.Println("Error initializing: " + err.Error())
 		os.Exit(1)
 	}
 
+	if parser.Active != nil {
+		switch parser.Active.Name {
+		case "changelog":
+			err = runChangelog(r)
+		case "explain":
+			err = runExplain(r, cfg)
+		}
+
+		if err != nil {
+			log.SetOutput(os.Stderr)
+			log.Printf("Error running %s: %s", parser.Active.Name, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Tag unless asked otherwise
 	if !opts.JustVersion {
 		err = r.AutoTag()
-		if err != nil {
+		if err != nil && !errors.Is(err, autotag.ErrNoRelease) {
 			log.SetOutput(os.Stderr)
 			log.Println("Error auto updating version: " + err.Error())
 			os.Exit(1)
 		}
 	}
 
-	fmt.Println(r.LatestVersion())
+	if opts.Output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r.Release()); err != nil {
+			log.SetOutput(os.Stderr)
+			log.Println("Error writing output: " + err.Error())
+			os.Exit(1)
+		}
+	} else {
+		fmt.Println(r.LatestVersion())
+	}
 
-	// TODO:(jnelson) Add -major -minor -patch flags for force bumps Fri Sep 11 10:04:20 2015
+	if r.Release().NoRelease {
+		log.Println("No release needed")
+		os.Exit(exitNoRelease)
+	}
 	os.Exit(0)
 }
+
+// repoConfig returns the library configuration for the CLI options, ignoring any config file
+func repoConfig(opts Options) autotag.GitRepoConfig {
+	return autotag.GitRepoConfig{
+		RepoPath:                  opts.RepoPath,
+		Promote:                   opts.Promote,
+		Branch:                    opts.Branch,
+		PreReleaseName:            opts.PreReleaseName,
+		PreReleaseTimestampLayout: opts.PreReleaseTimestamp,
+		PreReleaseCounter:         opts.PreReleaseCounter,
+		BuildMetadata:             opts.BuildMetadata,
+		Scheme:                    opts.Scheme,
+		Prefix:                    !opts.NoVersionPrefix,
+		AnnotatedTag:              opts.AnnotatedTag,
+		TagMessage:                opts.TagMessage,
+		SignTag:                   opts.Sign || opts.SigningKey != "",
+		SigningKey:                opts.SigningKey,
+		SigningFormat:             opts.SigningFormat,
+		PushRemote:                opts.Push,
+		PushRetries:               opts.PushRetries,
+		TagPrefix:                 opts.TagPrefix,
+		Paths:                     opts.Paths,
+		AllTags:                   opts.AllTags,
+		MaintenanceBranches:       opts.MaintenanceBranches,
+		InitialVersion:            opts.InitialVersion,
+		Bump:                      opts.Bump,
+		Version:                   opts.SetVersion,
+		InitialDevelopment:        opts.InitialDevelopment,
+		ConventionalTypes:         opts.ConventionalTypes,
+		SkipUnreleasable:          opts.SkipUnreleasable,
+		AlwaysTag:                 opts.AlwaysTag,
+		DeepenShallow:             opts.DeepenShallow,
+		Backend:                   opts.Backend,
+	}
+}
@@ -2,7 +2,12 @@ package autotag
 
 import (
 	"fmt"
+	"io"
+	"log"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -37,12 +42,36 @@ type testRepoSetup struct {
 	// (optional) the prerelease timestamp format to use, eg: "epoch". If not set, no prerelease timestamp will be used
 	preReleaseTimestampLayout string
 
+	// (optional) append an incrementing counter to the prerelease name
+	preReleaseCounter bool
+
 	// (optional) build metadata to append to the version
 	buildMetadata string
 
 	// (optional) prepend literal 'v' to version tags (default: true)
 	disablePrefix bool
 
+	// (optional) create annotated tags
+	annotatedTag bool
+
+	// (optional) template for the annotated tag message
+	tagMessage string
+
+	// (optional) create signed tags
+	signTag bool
+
+	// (optional) key to sign tags with
+	signingKey string
+
+	// (optional) signing format, eg: "ssh"
+	signingFormat string
+
+	// (optional) lower the bumps of 0.y.z versions
+	initialDevelopment bool
+
+	// (optional) repository backend, eg: "go-git". If not set, defaults to the git CLI
+	backend string
+
 	// (optional) commit message to use for the next, untagged commit. Settings this allows for testing the
 	// commit message parsing logic. eg: "#major this is a major commit"
 	nextCommit string
@@ -53,7 +82,7 @@ type testRepoSetup struct {
 
 // newTestRepo creates a new git repo in a temporary directory and returns an autotag.GitRepo struct for
 // testing the autotag package.
-// You must call cleanupTestRepo(t, r.repo) to remove the temporary directory after running tests.
+// You must call cleanupTestRepo(t, gitModuleRepo(t, &r)) to remove the temporary directory after running tests.
 func newTestRepo(t *testing.T, setup testRepoSetup) GitRepo {
 	tr := createTestRepo(t, setup.branch)
 
@@ -95,9 +124,17 @@ func newTestRepo(t *testing.T, setup testRepoSetup) GitRepo {
 		Branch:                    branch,
 		PreReleaseName:            setup.preReleaseName,
 		PreReleaseTimestampLayout: setup.preReleaseTimestampLayout,
+		PreReleaseCounter:         setup.preReleaseCounter,
 		BuildMetadata:             setup.buildMetadata,
 		Scheme:                    setup.scheme,
 		Prefix:                    !setup.disablePrefix,
+		AnnotatedTag:              setup.annotatedTag,
+		TagMessage:                setup.tagMessage,
+		SignTag:                   setup.signTag,
+		SigningKey:                setup.signingKey,
+		SigningFormat:             setup.signingFormat,
+		InitialDevelopment:        setup.initialDevelopment,
+		Backend:                   setup.backend,
 	})
 
 	if err != nil {
@@ -153,6 +190,132 @@ func TestValidateConfig(t *testing.T) {
 			},
 			shouldErr: true,
 		},
+		{
+			name: "invalid tag message template",
+			cfg: GitRepoConfig{
+				Branch:       "master",
+				AnnotatedTag: true,
+				TagMessage:   "{{.Version",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "invalid signing format",
+			cfg: GitRepoConfig{
+				Branch:        "master",
+				SignTag:       true,
+				SigningFormat: "pgp",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "signing key without signing",
+			cfg: GitRepoConfig{
+				Branch:     "master",
+				SigningKey: "ABCDEF12",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "unknown scheme",
+			cfg: GitRepoConfig{
+				Branch: "master",
+				Scheme: "semantic",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "pre-release counter without pre-release name",
+			cfg: GitRepoConfig{
+				Branch:            "master",
+				PreReleaseCounter: true,
+			},
+			shouldErr: true,
+		},
+		{
+			name: "pre-release counter with pre-release timestamp",
+			cfg: GitRepoConfig{
+				Branch:                    "master",
+				PreReleaseName:            "rc",
+				PreReleaseTimestampLayout: "epoch",
+				PreReleaseCounter:         true,
+			},
+			shouldErr: true,
+		},
+		{
+			name: "maintenance branch pattern without major",
+			cfg: GitRepoConfig{
+				Branch:              "master",
+				MaintenanceBranches: []string{"release/{minor}"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "initial version with pre-release",
+			cfg: GitRepoConfig{
+				Branch:         "master",
+				InitialVersion: "0.1.0-rc.1",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "incomplete initial version",
+			cfg: GitRepoConfig{
+				Branch:         "master",
+				InitialVersion: "0.1",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "invalid bump",
+			cfg: GitRepoConfig{
+				Branch: "master",
+				Bump:   "huge",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "invalid version",
+			cfg: GitRepoConfig{
+				Branch:  "master",
+				Version: "v2",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "version with bump",
+			cfg: GitRepoConfig{
+				Branch:  "master",
+				Bump:    "major",
+				Version: "2.0.0",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "conventional types without conventional scheme",
+			cfg: GitRepoConfig{
+				Branch:            "master",
+				ConventionalTypes: map[string]string{"perf": "minor"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "invalid conventional type bump",
+			cfg: GitRepoConfig{
+				Branch:            "master",
+				Scheme:            "conventional",
+				ConventionalTypes: map[string]string{"perf": "feature"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "unknown backend",
+			cfg: GitRepoConfig{
+				Branch:  "master",
+				Backend: "libgit2",
+			},
+			shouldErr: true,
+		},
 		{
 			name: "valid config with all options used",
 			cfg: GitRepoConfig{
@@ -160,7 +323,17 @@ func TestValidateConfig(t *testing.T) {
 				PreReleaseName:            "foo",
 				PreReleaseTimestampLayout: "epoch",
 				BuildMetadata:             "g12345678",
+				Scheme:                    "conventional",
 				Prefix:                    true,
+				AnnotatedTag:              true,
+				TagMessage:                "release {{.Version}}",
+				SignTag:                   true,
+				SigningKey:                "ABCDEF12",
+				SigningFormat:             "openpgp",
+				InitialVersion:            "0.1.0",
+				Bump:                      "minor",
+				ConventionalTypes:         map[string]string{"perf": "minor", "docs": "none"},
+				Backend:                   "go-git",
 			},
 			shouldErr: false,
 		},
@@ -190,167 +363,188 @@ func TestNewRepo(t *testing.T) {
 		{"master", "", "master"},
 	}
 
-	for _, tt := range newRepoTests {
-		tr := createTestRepo(t, tt.createBranch)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tt := range newRepoTests {
+			tr := createTestRepo(t, tt.createBranch)
 
-		repo, err := git.Open(tr)
-		checkFatal(t, err)
+			repo, err := git.Open(tr)
+			checkFatal(t, err)
 
-		tag := "v0.0.1"
-		seedTestRepo(t, tag, repo)
+			tag := "v0.0.1"
+			seedTestRepo(t, tag, repo)
 
-		r, err := NewRepo(GitRepoConfig{
-			Branch:   tt.requestBranch,
-			RepoPath: repo.Path(),
-		})
+			r, err := NewRepo(GitRepoConfig{
+				Branch:   tt.requestBranch,
+				RepoPath: repo.Path(),
+				Backend:  backend,
+			})
 
-		if err != nil {
-			t.Fatal("Error creating repo: ", err)
-		}
+			if err != nil {
+				t.Fatal("Error creating repo: ", err)
+			}
 
-		if r.branch != tt.expectBranch {
-			t.Fatalf("Expected branch %s, got [%s]", tt.expectBranch, r.branch)
+			if r.branch != tt.expectBranch {
+				t.Fatalf("Expected branch %s, got [%s]", tt.expectBranch, r.branch)
+			}
 		}
-	}
+	})
 }
 
 func TestNewRepoMainAndMaster(t *testing.T) {
-	// create repo w/"master" branch
-	tr := createTestRepo(t, "master")
-
-	repo, err := git.Open(tr)
-	checkFatal(t, err)
-
-	seedTestRepo(t, "v0.0.1", repo)
-
-	// also create "main" branch
-	f := repoRoot(repo) + "/main"
-	err = exec.Command("touch", f).Run()
-	if err != nil {
-		fmt.Println("FAILED to touch the file ", f, err)
-		checkFatal(t, err)
-	}
+	forEachBackend(t, func(t *testing.T, backend string) {
+		// create repo w/"master" branch
+		tr := createTestRepo(t, "master")
 
-	cmd := exec.Command("git", "checkout", "-b", "main")
-	cmd.Dir = repoRoot(repo)
-	err = cmd.Run()
-	if err != nil {
-		fmt.Println("FAILED to create/checkout main branch", err)
+		repo, err := git.Open(tr)
 		checkFatal(t, err)
-	}
 
-	makeCommit(repo, "this is a commit on main")
-	makeTag(repo, "v0.2.1")
-
-	// check results
-	var newRepoTests = []struct {
-		requestBranch string
-		expectBranch  string
-	}{
-		{"main", "main"},
-		{"master", "master"},
-		{"", "main"},
-	}
+		seedTestRepo(t, "v0.0.1", repo)
 
-	for _, tt := range newRepoTests {
-		r, err := NewRepo(GitRepoConfig{
-			Branch:   tt.requestBranch,
-			RepoPath: repo.Path(),
-		})
+		// also create "main" branch
+		f := repoRoot(repo) + "/main"
+		err = exec.Command("touch", f).Run()
+		if err != nil {
+			fmt.Println("FAILED to touch the file ", f, err)
+			checkFatal(t, err)
+		}
 
+		cmd := exec.Command("git", "checkout", "-b", "main")
+		cmd.Dir = repoRoot(repo)
+		err = cmd.Run()
 		if err != nil {
-			t.Fatal("Error creating repo: ", err)
+			fmt.Println("FAILED to create/checkout main branch", err)
+			checkFatal(t, err)
 		}
 
-		if r.branch != tt.expectBranch {
-			t.Fatalf("Expected branch %s, got [%s]", tt.expectBranch, r.branch)
+		makeCommit(repo, "this is a commit on main")
+		makeTag(repo, "v0.2.1")
+
+		// check results
+		var newRepoTests = []struct {
+			requestBranch string
+			expectBranch  string
+		}{
+			{"main", "main"},
+			{"master", "master"},
+			{"", "main"},
 		}
-	}
+
+		for _, tt := range newRepoTests {
+			r, err := NewRepo(GitRepoConfig{
+				Branch:   tt.requestBranch,
+				RepoPath: repo.Path(),
+				Backend:  backend,
+			})
+
+			if err != nil {
+				t.Fatal("Error creating repo: ", err)
+			}
+
+			if r.branch != tt.expectBranch {
+				t.Fatalf("Expected branch %s, got [%s]", tt.expectBranch, r.branch)
+			}
+		}
+	})
 }
 
 func TestMajor(t *testing.T) {
-	r := newTestRepo(t, testRepoSetup{
-		branch:     "master",
-		initialTag: "v1.0.1",
-	})
-	defer cleanupTestRepo(t, r.repo)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		r := newTestRepo(t, testRepoSetup{
+			branch:     "master",
+			initialTag: "v1.0.1",
+			backend:    backend,
+		})
+		defer cleanupTestRepo(t, gitModuleRepo(t, &r))
 
-	v, err := r.MajorBump()
-	if err != nil {
-		t.Fatal("MajorBump failed: ", err)
-	}
+		v, err := r.MajorBump()
+		if err != nil {
+			t.Fatal("MajorBump failed: ", err)
+		}
 
-	if v.String() != "2.0.0" {
-		t.Fatalf("MajorBump failed expected '2.0.0' got '%s' ", v)
-	}
+		if v.String() != "2.0.0" {
+			t.Fatalf("MajorBump failed expected '2.0.0' got '%s' ", v)
+		}
 
-	fmt.Printf("Major is now %s\n", v)
+		fmt.Printf("Major is now %s\n", v)
+	})
 }
 
 func TestMajorWithMain(t *testing.T) {
-	r := newTestRepo(t, testRepoSetup{
-		branch:     "main",
-		initialTag: "v1.0.1",
-	})
-	defer cleanupTestRepo(t, r.repo)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		r := newTestRepo(t, testRepoSetup{
+			branch:     "main",
+			initialTag: "v1.0.1",
+			backend:    backend,
+		})
+		defer cleanupTestRepo(t, gitModuleRepo(t, &r))
 
-	v, err := r.MajorBump()
-	if err != nil {
-		t.Fatal("MajorBump failed: ", err)
-	}
+		v, err := r.MajorBump()
+		if err != nil {
+			t.Fatal("MajorBump failed: ", err)
+		}
 
-	if v.String() != "2.0.0" {
-		t.Fatalf("MajorBump failed expected '2.0.0' got '%s' ", v)
-	}
+		if v.String() != "2.0.0" {
+			t.Fatalf("MajorBump failed expected '2.0.0' got '%s' ", v)
+		}
 
-	fmt.Printf("Major is now %s\n", v)
+		fmt.Printf("Major is now %s\n", v)
+	})
 }
 
 func TestMinor(t *testing.T) {
-	r := newTestRepo(t, testRepoSetup{
-		initialTag: "v1.0.1",
-	})
-	defer cleanupTestRepo(t, r.repo)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		r := newTestRepo(t, testRepoSetup{
+			initialTag: "v1.0.1",
+			backend:    backend,
+		})
+		defer cleanupTestRepo(t, gitModuleRepo(t, &r))
 
-	v, err := r.MinorBump()
-	if err != nil {
-		t.Fatal("MinorBump failed: ", err)
-	}
+		v, err := r.MinorBump()
+		if err != nil {
+			t.Fatal("MinorBump failed: ", err)
+		}
 
-	if v.String() != "1.1.0" {
-		t.Fatalf("MinorBump failed expected '1.1.0' got '%s' \n", v)
-	}
+		if v.String() != "1.1.0" {
+			t.Fatalf("MinorBump failed expected '1.1.0' got '%s' \n", v)
+		}
+	})
 }
 
 func TestPatch(t *testing.T) {
-	r := newTestRepo(t, testRepoSetup{
-		initialTag: "v1.0.1",
-	})
-	defer cleanupTestRepo(t, r.repo)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		r := newTestRepo(t, testRepoSetup{
+			initialTag: "v1.0.1",
+			backend:    backend,
+		})
+		defer cleanupTestRepo(t, gitModuleRepo(t, &r))
 
-	v, err := r.PatchBump()
-	if err != nil {
-		t.Fatal("PatchBump failed: ", err)
-	}
+		v, err := r.PatchBump()
+		if err != nil {
+			t.Fatal("PatchBump failed: ", err)
+		}
 
-	if v.String() != "1.0.2" {
-		t.Fatalf("PatchBump failed expected '1.0.2' got '%s' \n", v)
-	}
+		if v.String() != "1.0.2" {
+			t.Fatalf("PatchBump failed expected '1.0.2' got '%s' \n", v)
+		}
+	})
 }
 
 func TestMissingInitialTag(t *testing.T) {
-	tr := createTestRepo(t, "")
-	repo, err := git.Open(tr)
-	checkFatal(t, err)
-	defer cleanupTestRepo(t, repo)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+		defer cleanupTestRepo(t, repo)
 
-	updateReadme(t, repo, "a commit before any usable tag has been created")
+		updateReadme(t, repo, "a commit before any usable tag has been created")
 
-	_, err = NewRepo(GitRepoConfig{
-		RepoPath: repo.Path(),
-		Branch:   "master",
+		_, err = NewRepo(GitRepoConfig{
+			RepoPath: repo.Path(),
+			Branch:   "master",
+			Backend:  backend,
+		})
+		assert.Error(t, err)
 	})
-	assert.Error(t, err)
 }
 
 func TestAutoTag(t *testing.T) {
@@ -467,6 +661,54 @@ func TestAutoTag(t *testing.T) {
 			},
 			expectedTag: "v1.0.1",
 		},
+		{
+			name: "pre-release counter without existing pre-releases",
+			setup: testRepoSetup{
+				scheme:            "autotag",
+				nextCommit:        "#patch bump",
+				initialTag:        "v1.0.0",
+				preReleaseName:    "rc",
+				preReleaseCounter: true,
+			},
+			expectedTag: "v1.0.1-rc.1",
+		},
+		{
+			name: "pre-release counter increments existing pre-releases",
+			setup: testRepoSetup{
+				scheme:            "autotag",
+				nextCommit:        "#patch bump",
+				initialTag:        "v1.0.0",
+				extraTags:         []string{"v1.0.1-rc.1", "v1.0.1-rc.2+g1234", "v1.0.1-rc.10", "v1.0.1-rc"},
+				preReleaseName:    "rc",
+				preReleaseCounter: true,
+			},
+			expectedTag: "v1.0.1-rc.11",
+		},
+		{
+			name: "pre-release counter ignores other names and versions",
+			setup: testRepoSetup{
+				scheme:            "autotag",
+				nextCommit:        "#minor feature",
+				initialTag:        "v1.0.0",
+				extraTags:         []string{"v1.1.0-beta.4", "v1.0.1-rc.3", "v1.1.0-rc.x"},
+				preReleaseName:    "rc",
+				preReleaseCounter: true,
+			},
+			expectedTag: "v1.1.0-rc.1",
+		},
+		{
+			name: "pre-release counter with build metadata",
+			setup: testRepoSetup{
+				scheme:            "autotag",
+				nextCommit:        "#patch bump",
+				initialTag:        "v1.0.0",
+				extraTags:         []string{"v1.0.1-rc.1"},
+				preReleaseName:    "rc",
+				preReleaseCounter: true,
+				buildMetadata:     "g012345678",
+			},
+			expectedTag: "v1.0.1-rc.2+g012345678",
+		},
 		{
 			name: "build metadata",
 			setup: testRepoSetup{
@@ -637,23 +879,809 @@ func TestAutoTag(t *testing.T) {
 		},
 	}
 
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			r := newTestRepo(t, tc.setup)
-			defer cleanupTestRepo(t, r.repo)
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				setup := tc.setup
+				setup.backend = backend
+				r := newTestRepo(t, setup)
+				defer cleanupTestRepo(t, gitModuleRepo(t, &r))
+
+				err := r.AutoTag()
+				if tc.shouldErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+
+				tags, err := r.repo.Tags(tagFilter{})
+				checkFatal(t, err)
+				assert.Contains(t, tags, tc.expectedTag)
+			})
+		}
+	})
+}
 
-			err := r.AutoTag()
-			if tc.shouldErr {
-				assert.Error(t, err)
-			} else {
+func TestAnnotatedTag(t *testing.T) {
+	tests := []struct {
+		name            string
+		setup           testRepoSetup
+		expectedTag     string
+		expectedType    string
+		expectedMessage []string
+	}{
+		{
+			name: "lightweight tag by default",
+			setup: testRepoSetup{
+				nextCommit: "#minor add a feature",
+				initialTag: "v1.0.0",
+			},
+			expectedTag:  "v1.1.0",
+			expectedType: "commit",
+		},
+		{
+			name: "annotated tag with default message",
+			setup: testRepoSetup{
+				commitList:   []string{"#minor add a feature", "fix a bug"},
+				initialTag:   "v1.0.0",
+				annotatedTag: true,
+			},
+			expectedTag:     "v1.1.0",
+			expectedType:    "tag",
+			expectedMessage: []string{"Release v1.1.0", "minor bump from 1.0.0", "#minor add a feature", "fix a bug"},
+		},
+		{
+			name: "annotated tag with message template",
+			setup: testRepoSetup{
+				nextCommit:   "[major] drop the old API",
+				initialTag:   "v1.0.0",
+				annotatedTag: true,
+				tagMessage:   "{{.PreviousVersion}} -> {{.Version}} ({{.Bump}}){{range .Commits}} {{.Summary}}{{end}}",
+			},
+			expectedTag:     "v2.0.0",
+			expectedType:    "tag",
+			expectedMessage: []string{"1.0.0 -> 2.0.0 (major) [major] drop the old API"},
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				setup := tc.setup
+				setup.backend = backend
+				r := newTestRepo(t, setup)
+				defer cleanupTestRepo(t, gitModuleRepo(t, &r))
+
+				err := r.AutoTag()
 				assert.NoError(t, err)
-			}
 
-			tags, err := r.repo.Tags()
-			checkFatal(t, err)
-			assert.Contains(t, tags, tc.expectedTag)
-		})
+				assert.Equal(t, tc.expectedType, tagObjectType(t, gitModuleRepo(t, &r), tc.expectedTag))
+
+				msg := tagContents(t, gitModuleRepo(t, &r), tc.expectedTag)
+				for _, m := range tc.expectedMessage {
+					assert.Contains(t, msg, m)
+				}
+			})
+		}
+	})
+}
+
+func TestMonorepo(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "master")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+		defer cleanupTestRepo(t, repo)
+
+		seedTestRepo(t, "v5.0.0", repo)
+		makeTag(repo, "services/api/v1.0.0")
+		makeTag(repo, "services/web/v2.0.0")
+		makeTag(repo, "services/worker/v0.3.0")
+
+		commitFile(t, repo, "services/api/main.go", "#minor api feature")
+		commitFile(t, repo, "services/web/main.go", "#major web rewrite")
+		commitFile(t, repo, "services/api/handler.go", "api fix")
+		commitFile(t, repo, "docs/README.md", "#major docs rewrite")
+
+		tests := []struct {
+			name              string
+			tagPrefix         string
+			paths             []string
+			expectedTag       string
+			expectedNoRelease bool
+		}{
+			{
+				name:        "api component",
+				tagPrefix:   "services/api/",
+				paths:       []string{"services/api"},
+				expectedTag: "services/api/v1.1.0",
+			},
+			{
+				name:        "web component",
+				tagPrefix:   "services/web/",
+				paths:       []string{"services/web"},
+				expectedTag: "services/web/v3.0.0",
+			},
+			{
+				name:        "multiple paths",
+				tagPrefix:   "services/api/",
+				paths:       []string{"services/api", "docs"},
+				expectedTag: "services/api/v2.0.0",
+			},
+			{
+				name:              "component without changes",
+				tagPrefix:         "services/worker/",
+				paths:             []string{"services/worker"},
+				expectedTag:       "services/worker/v0.3.0",
+				expectedNoRelease: true,
+			},
+			{
+				name:        "whole repository ignores prefixed tags",
+				expectedTag: "v6.0.0",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:  repo.Path(),
+					Branch:    "master",
+					Prefix:    true,
+					TagPrefix: tc.tagPrefix,
+					Paths:     tc.paths,
+					Backend:   backend,
+				})
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedTag, r.tagName())
+				assert.Equal(t, tc.expectedNoRelease, r.Release().NoRelease)
+			})
+		}
+	})
+}
+
+func TestReachableTags(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "master")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+		defer cleanupTestRepo(t, repo)
+
+		seedTestRepo(t, "v1.0.0", repo)
+
+		// a v2 release on a branch that is not merged into master
+		runGit(t, tr, "checkout", "-b", "feature")
+		updateReadme(t, repo, "[major] rewrite everything")
+		makeTag(repo, "v2.0.0")
+		runGit(t, tr, "checkout", "master")
+
+		updateReadme(t, repo, "fix a bug on master")
+
+		tests := []struct {
+			name            string
+			allTags         bool
+			expectedVersion string
+		}{
+			{
+				name:            "only tags reachable from the branch",
+				expectedVersion: "1.0.1",
+			},
+			{
+				name:            "all tags",
+				allTags:         true,
+				expectedVersion: "2.0.1",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath: repo.Path(),
+					Branch:   "master",
+					AllTags:  tc.allTags,
+					Backend:  backend,
+				})
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedVersion, r.LatestVersion())
+			})
+		}
+	})
+}
+
+func TestMaintenanceBranches(t *testing.T) {
+	tests := []struct {
+		name            string
+		branch          string
+		initialTag      string
+		commit          string
+		shouldErr       bool
+		expectedVersion string
+	}{
+		{
+			name:            "patch bump on a minor line",
+			branch:          "release/1.4",
+			initialTag:      "v1.4.2",
+			commit:          "fix a bug",
+			expectedVersion: "1.4.3",
+		},
+		{
+			name:       "minor bump leaves a minor line",
+			branch:     "release/1.4",
+			initialTag: "v1.4.2",
+			commit:     "#minor add a feature",
+			shouldErr:  true,
+		},
+		{
+			name:       "major bump leaves a minor line",
+			branch:     "release/1.4",
+			initialTag: "v1.4.2",
+			commit:     "[major] break everything",
+			shouldErr:  true,
+		},
+		{
+			name:            "minor bump on a major line",
+			branch:          "support/v2",
+			initialTag:      "v2.3.0",
+			commit:          "#minor add a feature",
+			expectedVersion: "2.4.0",
+		},
+		{
+			name:       "major bump leaves a major line",
+			branch:     "support/v2",
+			initialTag: "v2.3.0",
+			commit:     "[major] break everything",
+			shouldErr:  true,
+		},
+		{
+			name:            "branches not matching a pattern are unrestricted",
+			branch:          "master",
+			initialTag:      "v1.4.2",
+			commit:          "[major] break everything",
+			expectedVersion: "2.0.0",
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tr := createTestRepo(t, tc.branch)
+				repo, err := git.Open(tr)
+				checkFatal(t, err)
+
+				seedTestRepo(t, tc.initialTag, repo)
+				updateReadme(t, repo, tc.commit)
+
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:            repo.Path(),
+					Branch:              tc.branch,
+					MaintenanceBranches: []string{"release/{major}.{minor}", "support/v{major}"},
+					Backend:             backend,
+				})
+				if tc.shouldErr {
+					assert.Error(t, err)
+					return
+				}
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedVersion, r.LatestVersion())
+			})
+		}
+	})
+}
+
+func TestInitialVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		scheme          string
+		tag             string
+		initialVersion  string
+		commits         []string
+		shouldErr       bool
+		expectedVersion string
+		expectedBump    string
+	}{
+		{
+			name:      "no tags without initial version",
+			commits:   []string{"add a feature"},
+			shouldErr: true,
+		},
+		{
+			name:            "initial version",
+			initialVersion:  "0.1.0",
+			commits:         []string{"initial commit", "fix a bug"},
+			expectedVersion: "0.1.0",
+		},
+		{
+			name:            "commits requesting a higher version",
+			scheme:          "conventional",
+			initialVersion:  "0.1.0",
+			commits:         []string{"feat: initial commit", "feat!: rework the api"},
+			expectedVersion: "1.0.0",
+			expectedBump:    "major",
+		},
+		{
+			name:            "initial version is ignored when tagged",
+			tag:             "v1.0.0",
+			initialVersion:  "0.1.0",
+			commits:         []string{"initial commit", "fix a bug"},
+			expectedVersion: "1.0.1",
+			expectedBump:    "patch",
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tr := createTestRepo(t, "")
+				repo, err := git.Open(tr)
+				checkFatal(t, err)
+
+				for _, c := range tc.commits {
+					updateReadme(t, repo, c)
+				}
+				if tc.tag != "" {
+					makeTag(repo, tc.tag)
+					updateReadme(t, repo, "after the tag")
+				}
+
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:       repo.Path(),
+					Branch:         "master",
+					Scheme:         tc.scheme,
+					Prefix:         true,
+					InitialVersion: tc.initialVersion,
+					Backend:        backend,
+				})
+				if tc.shouldErr {
+					assert.Error(t, err)
+					return
+				}
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedVersion, r.LatestVersion())
+
+				rel := r.Release()
+				assert.Equal(t, tc.expectedBump, rel.Bump)
+				if tc.tag == "" {
+					assert.Equal(t, "", rel.PreviousTag)
+					assert.Equal(t, len(tc.commits), len(rel.Commits))
+				}
+
+				checkFatal(t, r.AutoTag())
+				assert.Equal(t, "v"+tc.expectedVersion, rel.Tag)
+			})
+		}
+	})
+}
+
+func TestForcedVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		bump            string
+		version         string
+		commit          string
+		shouldErr       bool
+		expectedVersion string
+		expectedBump    string
+	}{
+		{
+			name:            "forced major bump",
+			bump:            "major",
+			commit:          "fix a bug",
+			expectedVersion: "2.0.0",
+			expectedBump:    "major",
+		},
+		{
+			name:            "forced patch bump overrides commits",
+			bump:            "patch",
+			commit:          "[major] break everything",
+			expectedVersion: "1.2.4",
+			expectedBump:    "patch",
+		},
+		{
+			name:            "explicit version",
+			version:         "1.5.0",
+			commit:          "fix a bug",
+			expectedVersion: "1.5.0",
+			expectedBump:    "minor",
+		},
+		{
+			name:      "explicit version equal to the current version",
+			version:   "1.2.3",
+			commit:    "fix a bug",
+			shouldErr: true,
+		},
+		{
+			name:      "explicit version lower than the current version",
+			version:   "1.0.0",
+			commit:    "fix a bug",
+			shouldErr: true,
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tr := createTestRepo(t, "")
+				repo, err := git.Open(tr)
+				checkFatal(t, err)
+
+				seedTestRepo(t, "v1.2.3", repo)
+				updateReadme(t, repo, tc.commit)
+
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath: repo.Path(),
+					Branch:   "master",
+					Bump:     tc.bump,
+					Version:  tc.version,
+					Backend:  backend,
+				})
+				if tc.shouldErr {
+					assert.Error(t, err)
+					return
+				}
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedVersion, r.LatestVersion())
+				assert.Equal(t, tc.expectedBump, r.Release().Bump)
+			})
+		}
+	})
+}
+
+func TestInitialDevelopment(t *testing.T) {
+	tests := []struct {
+		name            string
+		scheme          string
+		initialTag      string
+		bump            string
+		commit          string
+		expectedVersion string
+	}{
+		{
+			name:            "major bump becomes minor",
+			initialTag:      "v0.3.1",
+			commit:          "[major] break everything",
+			expectedVersion: "0.4.0",
+		},
+		{
+			name:            "minor bump becomes patch",
+			initialTag:      "v0.3.1",
+			commit:          "#minor add a feature",
+			expectedVersion: "0.3.2",
+		},
+		{
+			name:            "conventional breaking change",
+			scheme:          "conventional",
+			initialTag:      "v0.3.1",
+			commit:          "feat!: drop the old api",
+			expectedVersion: "0.4.0",
+		},
+		{
+			name:            "conventional feature",
+			scheme:          "conventional",
+			initialTag:      "v0.3.1",
+			commit:          "feat: add a feature",
+			expectedVersion: "0.3.2",
+		},
+		{
+			name:            "forced major bump leaves 0.x",
+			initialTag:      "v0.3.1",
+			bump:            "major",
+			commit:          "fix a bug",
+			expectedVersion: "1.0.0",
+		},
+		{
+			name:            "stable versions are unaffected",
+			initialTag:      "v1.2.0",
+			commit:          "[major] break everything",
+			expectedVersion: "2.0.0",
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tr := createTestRepo(t, "")
+				repo, err := git.Open(tr)
+				checkFatal(t, err)
+
+				seedTestRepo(t, tc.initialTag, repo)
+				updateReadme(t, repo, tc.commit)
+
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:           repo.Path(),
+					Branch:             "master",
+					Scheme:             tc.scheme,
+					Bump:               tc.bump,
+					InitialDevelopment: true,
+					Backend:            backend,
+				})
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedVersion, r.LatestVersion())
+			})
+		}
+	})
+}
+
+func TestConventionalTypes(t *testing.T) {
+	types := map[string]string{"perf": "minor", "docs": "none", "chore": "none", "feat": "minor"}
+
+	tests := []struct {
+		name            string
+		commits         []string
+		expectedVersion string
+		expectedBumps   []string
+	}{
+		{
+			name:            "perf is a minor bump",
+			commits:         []string{"perf: cache lookups", "fix: typo"},
+			expectedVersion: "1.1.0",
+			expectedBumps:   []string{"minor", "unspecified"},
+		},
+		{
+			name:            "docs don't request a bump",
+			commits:         []string{"docs: explain caching", "chore: bump deps"},
+			expectedVersion: "1.0.1",
+			expectedBumps:   []string{"none", "none"},
+		},
+		{
+			name:            "breaking changes are still major",
+			commits:         []string{"docs!: drop the old guide"},
+			expectedVersion: "2.0.0",
+			expectedBumps:   []string{"major"},
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tr := createTestRepo(t, "")
+				repo, err := git.Open(tr)
+				checkFatal(t, err)
+
+				seedTestRepo(t, "v1.0.0", repo)
+				for _, c := range tc.commits {
+					updateReadme(t, repo, c)
+				}
+
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:          repo.Path(),
+					Branch:            "master",
+					Scheme:            "conventional",
+					ConventionalTypes: types,
+					Backend:           backend,
+				})
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedVersion, r.LatestVersion())
+
+				var bumps []string
+				for _, c := range r.Release().Commits {
+					bumps = append(bumps, c.Bump)
+				}
+				assert.Equal(t, tc.expectedBumps, bumps)
+			})
+		}
+	})
+}
+
+func TestSkipUnreleasable(t *testing.T) {
+	tests := []struct {
+		name              string
+		skipUnreleasable  bool
+		alwaysTag         bool
+		bump              string
+		commits           []string
+		expectedVersion   string
+		expectedNoRelease bool
+	}{
+		{
+			name:              "no commits since the tag",
+			skipUnreleasable:  true,
+			expectedVersion:   "1.0.0",
+			expectedNoRelease: true,
+		},
+		{
+			name:              "only unreleasable commits",
+			skipUnreleasable:  true,
+			commits:           []string{"docs: explain caching", "chore: bump deps"},
+			expectedVersion:   "1.0.0",
+			expectedNoRelease: true,
+		},
+		{
+			name:             "releasable commit",
+			skipUnreleasable: true,
+			commits:          []string{"docs: explain caching", "fix: typo"},
+			expectedVersion:  "1.0.1",
+		},
+		{
+			name:             "forced bump",
+			skipUnreleasable: true,
+			bump:             "minor",
+			expectedVersion:  "1.1.0",
+		},
+		{
+			name:            "patch bump without skipping",
+			alwaysTag:       true,
+			expectedVersion: "1.0.1",
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tr := createTestRepo(t, "")
+				repo, err := git.Open(tr)
+				checkFatal(t, err)
+
+				seedTestRepo(t, "v1.0.0", repo)
+				for _, c := range tc.commits {
+					updateReadme(t, repo, c)
+				}
+
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:          repo.Path(),
+					Branch:            "master",
+					Scheme:            "conventional",
+					Prefix:            true,
+					ConventionalTypes: map[string]string{"docs": "none", "chore": "none"},
+					SkipUnreleasable:  tc.skipUnreleasable,
+					AlwaysTag:         tc.alwaysTag,
+					Bump:              tc.bump,
+					Backend:           backend,
+				})
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedVersion, r.LatestVersion())
+				assert.Equal(t, tc.expectedNoRelease, r.Release().NoRelease)
+
+				err = r.AutoTag()
+				if tc.expectedNoRelease {
+					assert.Equal(t, ErrNoRelease, err)
+					tags, err := r.repo.Tags(tagFilter{})
+					checkFatal(t, err)
+					assert.Equal(t, []string{"v1.0.0"}, tags)
+					return
+				}
+				checkFatal(t, err)
+			})
+		}
+	})
+}
+
+func TestExistingTag(t *testing.T) {
+	tests := []struct {
+		name            string
+		noEarlierTag    bool
+		headTags        []string
+		preReleaseName  string
+		tagPrefix       string
+		disablePrefix   bool
+		version         string
+		bump            string
+		alwaysTag       bool
+		expectedTag     string
+		expectedCreated bool
+	}{
+		{
+			name:        "stable tag on the commit",
+			headTags:    []string{"v1.0.1"},
+			expectedTag: "v1.0.1",
+		},
+		{
+			name:            "always tag",
+			headTags:        []string{"v1.0.1"},
+			alwaysTag:       true,
+			expectedTag:     "v1.0.2",
+			expectedCreated: true,
+		},
+		{
+			name:           "pre-release tag with the same name",
+			headTags:       []string{"v1.0.1-rc.1"},
+			preReleaseName: "rc",
+			expectedTag:    "v1.0.1-rc.1",
+		},
+		{
+			name:            "pre-release tag when releasing stable",
+			headTags:        []string{"v1.0.1-rc.1"},
+			expectedTag:     "v1.0.1",
+			expectedCreated: true,
+		},
+		{
+			name:            "tag without the version prefix",
+			headTags:        []string{"1.0.1"},
+			expectedTag:     "v1.0.2",
+			expectedCreated: true,
+		},
+		{
+			name:            "tag of another component",
+			headTags:        []string{"services/web/v3.0.0"},
+			tagPrefix:       "services/api/",
+			expectedTag:     "services/api/v0.0.1",
+			expectedCreated: true,
+		},
+		{
+			name:        "explicit version already tagged",
+			headTags:    []string{"v2.0.0"},
+			version:     "2.0.0",
+			expectedTag: "v2.0.0",
+		},
+		{
+			name:            "explicit version differing from the tag",
+			headTags:        []string{"v1.0.1"},
+			version:         "2.0.0",
+			expectedTag:     "v2.0.0",
+			expectedCreated: true,
+		},
+		{
+			name:            "forced bump beyond the tag",
+			headTags:        []string{"v1.0.1"},
+			bump:            "major",
+			expectedTag:     "v2.0.0",
+			expectedCreated: true,
+		},
+		{
+			name:        "forced bump already tagged",
+			headTags:    []string{"v1.0.1", "v2.0.0"},
+			bump:        "major",
+			expectedTag: "v2.0.0",
+		},
+		{
+			name:        "tag beyond the forced bump",
+			headTags:    []string{"v1.1.0"},
+			bump:        "patch",
+			expectedTag: "v1.1.0",
+		},
+		{
+			name:            "forced bump of the only tag",
+			noEarlierTag:    true,
+			headTags:        []string{"v1.0.0"},
+			bump:            "minor",
+			expectedTag:     "v1.1.0",
+			expectedCreated: true,
+		},
 	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tr := createTestRepo(t, "")
+				repo, err := git.Open(tr)
+				checkFatal(t, err)
+
+				if tc.noEarlierTag {
+					seedTestRepo(t, "not-a-version", repo)
+				} else {
+					seedTestRepo(t, "v1.0.0", repo)
+				}
+				if tc.tagPrefix != "" {
+					makeTag(repo, tc.tagPrefix+"v0.0.0")
+				}
+				updateReadme(t, repo, "fix a bug")
+				for _, tag := range tc.headTags {
+					makeTag(repo, tag)
+				}
+
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:       repo.Path(),
+					Branch:         "master",
+					PreReleaseName: tc.preReleaseName,
+					TagPrefix:      tc.tagPrefix,
+					Prefix:         true,
+					Version:        tc.version,
+					Bump:           tc.bump,
+					AlwaysTag:      tc.alwaysTag,
+					Backend:        backend,
+				})
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedTag, r.Release().Tag)
+
+				before, err := r.repo.Tags(tagFilter{})
+				checkFatal(t, err)
+				checkFatal(t, r.AutoTag())
+				after, err := r.repo.Tags(tagFilter{})
+				checkFatal(t, err)
+
+				assert.Equal(t, tc.expectedCreated, len(after) == len(before)+1)
+				assert.True(t, containsString(after, tc.expectedTag))
+			})
+		}
+	})
 }
 
 func TestValidateSemVerBuildMetadata(t *testing.T) {
@@ -691,3 +1719,146 @@ func TestValidateSemVerBuildMetadata(t *testing.T) {
 		})
 	}
 }
+
+func TestBranchRevision(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+
+		seedTestRepo(t, "v1.0.0", repo)
+		updateReadme(t, repo, "#minor add a feature")
+		updateReadme(t, repo, "fix a bug")
+
+		// a CI checkout: detached HEAD with only remote-tracking branches
+		clone := filepath.Join(t.TempDir(), "clone")
+		runGit(t, repoRoot(repo), "clone", repoRoot(repo), clone)
+		runGit(t, clone, "checkout", "--detach", "HEAD~1")
+		runGit(t, clone, "branch", "-D", "master")
+
+		tests := []struct {
+			name            string
+			branch          string
+			expectedVersion string
+			expectedCommit  string
+		}{
+			{
+				name:            "remote-tracking branch fallback",
+				branch:          "master",
+				expectedVersion: "1.1.0",
+				expectedCommit:  "origin/master",
+			},
+			{
+				name:            "default branch on the remote",
+				expectedVersion: "1.1.0",
+				expectedCommit:  "origin/master",
+			},
+			{
+				name:            "remote branch",
+				branch:          "origin/master",
+				expectedVersion: "1.1.0",
+				expectedCommit:  "origin/master",
+			},
+			{
+				name:            "detached HEAD",
+				branch:          "HEAD",
+				expectedVersion: "1.1.0",
+				expectedCommit:  "HEAD",
+			},
+			{
+				name:            "commit id",
+				branch:          runGit(t, clone, "rev-parse", "origin/master~2"),
+				expectedVersion: "1.0.1",
+				expectedCommit:  "origin/master~2",
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:  clone,
+					Branch:    tc.branch,
+					Prefix:    true,
+					AlwaysTag: true,
+					Backend:   backend,
+				})
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedVersion, r.LatestVersion())
+
+				checkFatal(t, r.AutoTag())
+				defer runGit(t, clone, "tag", "--delete", "v"+tc.expectedVersion)
+				assert.Equal(t, runGit(t, clone, "rev-parse", tc.expectedCommit), runGit(t, clone, "rev-parse", "v"+tc.expectedVersion+"^{commit}"))
+			})
+		}
+
+		_, err = NewRepo(GitRepoConfig{RepoPath: clone, Branch: "missing", Backend: backend})
+		assert.Error(t, err)
+	})
+}
+
+// benchmarkTags is the number of tags in the repository BenchmarkParseTags builds
+const benchmarkTags = 3000
+
+// createManyTagsRepo builds a repository with one commit per tag in a single git fast-import run.
+// Every third tag is annotated and every tenth one a pre-release.
+func createManyTagsRepo(b *testing.B, tags int) string {
+	path := filepath.Join(b.TempDir(), "manyTags")
+	if out, err := exec.Command("git", "init", "-q", path).CombinedOutput(); err != nil {
+		b.Fatalf("git init failed: %s: %s", err, out)
+	}
+
+	var stream strings.Builder
+	data := func(s string) {
+		fmt.Fprintf(&stream, "data %d\n%s\n", len(s), s)
+	}
+	for i := 1; i <= tags; i++ {
+		when := 1500000000 + i*60
+		fmt.Fprintf(&stream, "commit refs/heads/master\nmark :%d\ncommitter ci <ci@example.com> %d +0000\n", i, when)
+		data(fmt.Sprintf("fix bug %d", i))
+
+		tag := fmt.Sprintf("v%d.%d.%d", i/1000+1, i/100%10, i%100)
+		if i%10 == 0 {
+			tag += "-rc.1"
+		}
+		if i%3 == 0 {
+			fmt.Fprintf(&stream, "tag %s\nfrom :%d\ntagger ci <ci@example.com> %d +0000\n", tag, i, when)
+			data("release " + tag)
+		} else {
+			fmt.Fprintf(&stream, "reset refs/tags/%s\nfrom :%d\n\n", tag, i)
+		}
+	}
+
+	cmd := exec.Command("git", "fast-import", "--quiet")
+	cmd.Dir = path
+	cmd.Stdin = strings.NewReader(stream.String())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		b.Fatalf("git fast-import failed: %s: %s", err, out)
+	}
+	return path
+}
+
+func BenchmarkParseTags(b *testing.B) {
+	path := createManyTagsRepo(b, benchmarkTags)
+
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for _, backend := range backendNames() {
+		b.Run(backend, func(b *testing.B) {
+			r, err := NewRepo(GitRepoConfig{RepoPath: path, Branch: "master", Prefix: true, Backend: backend})
+			if err != nil {
+				b.Fatal("Error creating repo: ", err)
+			}
+			if r.currentTagName != "v3.9.99" {
+				b.Fatalf("expected the current tag to be v3.9.99, got %s", r.currentTagName)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := r.parseTags(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
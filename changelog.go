@@ -0,0 +1,192 @@
+package autotag
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// changelogHeader starts a new changelog in Keep a Changelog format
+// https://keepachangelog.com/en/1.1.0/
+const changelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// changelogLevelHeadings are the section headings for each bump level, in the order they are rendered
+var changelogLevelHeadings = []struct {
+	level   BumpLevel
+	heading string
+}{
+	{BumpMajor, "Breaking Changes"},
+	{BumpMinor, "Features"},
+	{BumpPatch, "Fixes"},
+	{BumpUnspecified, "Other Changes"},
+	{BumpNone, "Other Changes"},
+}
+
+// conventionalTypeHeadings are the section headings for well known conventional commit types, in
+// the order they are rendered. Other types use the type itself as the heading and follow these.
+var conventionalTypeHeadings = []struct {
+	typ     string
+	heading string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"revert", "Reverts"},
+	{"refactor", "Code Refactoring"},
+	{"docs", "Documentation"},
+	{"style", "Styles"},
+	{"test", "Tests"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"chore", "Chores"},
+}
+
+// changelogEntry is a single line of a changelog section
+type changelogEntry struct {
+	scope string
+	text  string
+}
+
+// Changelog renders the commits of the release as a Markdown section in Keep a Changelog format,
+// eg:
+//
+//	## [1.2.0] - 2020-05-18
+//
+//	### Features
+//
+//	- add polish language (a1b2c3d)
+//
+// Commits are grouped by the bump they requested. With the "conventional" scheme they are grouped
+// by commit type instead, breaking changes first, and each entry is prefixed with its scope.
+func (r *GitRepo) Changelog() string {
+	var sections []string
+	groups := make(map[string][]changelogEntry)
+
+	if r.schemeName == "conventional" {
+		sections = append(sections, "BREAKING CHANGES")
+		for _, h := range conventionalTypeHeadings {
+			sections = append(sections, h.heading)
+		}
+
+		var otherTypes []string
+		for _, c := range r.commits {
+			heading, entry := conventionalChangelogEntry(c)
+			if _, ok := groups[heading]; !ok && !containsString(sections, heading) && heading != "Other Changes" {
+				otherTypes = append(otherTypes, heading)
+			}
+			groups[heading] = append(groups[heading], entry)
+		}
+		sections = append(sections, otherTypes...)
+		sections = append(sections, "Other Changes")
+	} else {
+		for _, h := range changelogLevelHeadings {
+			if !containsString(sections, h.heading) {
+				sections = append(sections, h.heading)
+			}
+		}
+		for _, c := range r.commits {
+			for _, h := range changelogLevelHeadings {
+				if h.level == c.bump {
+					groups[h.heading] = append(groups[h.heading], changelogEntry{text: commitEntryText(c.Summary(), c)})
+				}
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "## [%s] - %s\n", r.newVersion, timeNow().UTC().Format("2006-01-02"))
+	for _, heading := range sections {
+		entries := groups[heading]
+		if len(entries) == 0 {
+			continue
+		}
+
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].scope < entries[j].scope })
+
+		fmt.Fprintf(buf, "\n### %s\n\n", heading)
+		for _, e := range entries {
+			if e.scope != "" {
+				fmt.Fprintf(buf, "- **%s:** %s\n", e.scope, e.text)
+			} else {
+				fmt.Fprintf(buf, "- %s\n", e.text)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// conventionalChangelogEntry returns the section heading and the entry for a conventional commit
+func conventionalChangelogEntry(c inspectedCommit) (string, changelogEntry) {
+	matches := findNamedMatches(conventionalCommitRex, c.Summary())
+	subject := strings.TrimSpace(strings.TrimPrefix(matches["subject"], ":"))
+	if subject == "" {
+		return "Other Changes", changelogEntry{text: commitEntryText(c.Summary(), c)}
+	}
+
+	entry := changelogEntry{
+		scope: strings.Trim(strings.TrimSuffix(matches["scope"], "!"), "()"),
+		text:  commitEntryText(subject, c),
+	}
+
+	// the message decides, since InitialDevelopment lowers the bump of breaking changes on 0.x
+	if conventionalBreakingChange(c.Message, matches) != "" {
+		return "BREAKING CHANGES", entry
+	}
+
+	typ := strings.ToLower(matches["type"])
+	for _, h := range conventionalTypeHeadings {
+		if h.typ == typ {
+			return h.heading, entry
+		}
+	}
+	return typ, entry
+}
+
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// commitEntryText returns a changelog line for a commit: the text followed by the abbreviated id
+func commitEntryText(text string, c inspectedCommit) string {
+	id := c.ID.String()
+	if len(id) > 7 {
+		id = id[:7]
+	}
+	return fmt.Sprintf("%s (%s)", text, id)
+}
+
+// PrependChangelog inserts a changelog section, as returned by GitRepo.Changelog, into an existing
+// changelog in Keep a Changelog format. The section is placed before the most recent release,
+// after the header and any [Unreleased] section. If the changelog is empty a Keep a Changelog
+// header is added.
+func PrependChangelog(changelog, section string) string {
+	if strings.TrimSpace(changelog) == "" {
+		return changelogHeader + "\n" + section
+	}
+
+	lines := strings.SplitAfter(changelog, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "## ") || strings.HasPrefix(strings.ToLower(line), "## [unreleased]") {
+			continue
+		}
+		return strings.Join(lines[:i], "") + section + "\n" + strings.Join(lines[i:], "")
+	}
+
+	// no previous release: append the section to the end of the changelog
+	if !strings.HasSuffix(changelog, "\n") {
+		changelog += "\n"
+	}
+	return changelog + "\n" + section
+}
@@ -0,0 +1,173 @@
+package autotag
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alecthomas/assert"
+)
+
+func TestChangelog(t *testing.T) {
+	tests := []struct {
+		name               string
+		scheme             string
+		initialTag         string
+		initialDevelopment bool
+		commits            []string
+		expected           string // format string taking the short ids of the commits, oldest first
+	}{
+		{
+			name:    "autotag scheme groups by bump level",
+			scheme:  "autotag",
+			commits: []string{"#minor add a feature", "fix a bug", "[major] drop the v1 API", "#patch fix another bug"},
+			expected: `## [2.0.0] - 2019-01-01
+
+### Breaking Changes
+
+- [major] drop the v1 API (%[3]s)
+
+### Features
+
+- #minor add a feature (%[1]s)
+
+### Fixes
+
+- #patch fix another bug (%[4]s)
+
+### Other Changes
+
+- fix a bug (%[2]s)
+`,
+		},
+		{
+			name:    "registered scheme lists unreleasable commits as other changes",
+			scheme:  "test-jira",
+			commits: []string{"STORY-12 add a report", "CHORE-34 update the build", "fix a typo"},
+			expected: `## [1.1.0] - 2019-01-01
+
+### Features
+
+- STORY-12 add a report (%[1]s)
+
+### Other Changes
+
+- CHORE-34 update the build (%[2]s)
+- fix a typo (%[3]s)
+`,
+		},
+		{
+			name:    "conventional scheme groups by type and scope",
+			scheme:  "conventional",
+			commits: []string{"fix(ui): align buttons", "feat: add polish language", "docs: typo", "refactor(api)!: drop v1", "fix: crash on start", "not conventional", "wip(db): migrations"},
+			expected: `## [2.0.0] - 2019-01-01
+
+### BREAKING CHANGES
+
+- **api:** drop v1 (%[4]s)
+
+### Features
+
+- add polish language (%[2]s)
+
+### Bug Fixes
+
+- crash on start (%[5]s)
+- **ui:** align buttons (%[1]s)
+
+### Documentation
+
+- typo (%[3]s)
+
+### wip
+
+- **db:** migrations (%[7]s)
+
+### Other Changes
+
+- not conventional (%[6]s)
+`,
+		},
+		{
+			name:               "conventional breaking changes in initial development",
+			scheme:             "conventional",
+			initialTag:         "v0.3.0",
+			initialDevelopment: true,
+			commits:            []string{"feat!: drop v1", "feat: add polish language", "fix(api): rename field\n\nBREAKING CHANGE: clients must update"},
+			expected: `## [0.4.0] - 2019-01-01
+
+### BREAKING CHANGES
+
+- drop v1 (%[1]s)
+- **api:** rename field (%[3]s)
+
+### Features
+
+- add polish language (%[2]s)
+`,
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				initialTag := tc.initialTag
+				if initialTag == "" {
+					initialTag = "v1.0.0"
+				}
+
+				r := newTestRepo(t, testRepoSetup{
+					scheme:             tc.scheme,
+					initialTag:         initialTag,
+					initialDevelopment: tc.initialDevelopment,
+					commitList:         tc.commits,
+					backend:            backend,
+				})
+				defer cleanupTestRepo(t, gitModuleRepo(t, &r))
+
+				ids := make([]interface{}, len(tc.commits))
+				for i := range tc.commits {
+					ids[i] = runGit(t, repoRoot(gitModuleRepo(t, &r)), "rev-parse", "--short=7", fmt.Sprintf("master~%d", len(tc.commits)-1-i))
+				}
+
+				assert.Equal(t, fmt.Sprintf(tc.expected, ids...), r.Changelog())
+			})
+		}
+	})
+}
+
+func TestPrependChangelog(t *testing.T) {
+	section := "## [1.1.0] - 2019-01-01\n\n### Features\n\n- a feature (abcdef1)\n"
+
+	tests := []struct {
+		name      string
+		changelog string
+		expected  string
+	}{
+		{
+			name:      "new changelog",
+			changelog: "",
+			expected:  changelogHeader + "\n" + section,
+		},
+		{
+			name:      "before the latest release",
+			changelog: "# Changelog\n\n## [1.0.0] - 2018-12-01\n\n- first release\n",
+			expected:  "# Changelog\n\n" + section + "\n## [1.0.0] - 2018-12-01\n\n- first release\n",
+		},
+		{
+			name:      "after the unreleased section",
+			changelog: "# Changelog\n\n## [Unreleased]\n\n- upcoming\n\n## [1.0.0] - 2018-12-01\n",
+			expected:  "# Changelog\n\n## [Unreleased]\n\n- upcoming\n\n" + section + "\n## [1.0.0] - 2018-12-01\n",
+		},
+		{
+			name:      "no previous release",
+			changelog: "# Changelog\n\nSome text",
+			expected:  "# Changelog\n\nSome text\n\n" + section,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, PrependChangelog(tc.changelog, section))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package autotag
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileNames are the names of the config files LoadConfigFile looks for at the root of a
+// repository, in order of preference.
+var ConfigFileNames = []string{".autotag.yml", ".autotag.yaml", ".autotag.toml"}
+
+// LoadConfigFile reads the first of ConfigFileNames found at the root of the working tree containing
+// repoPath into cfg, eg:
+//
+//	scheme: conventional
+//	prefix: false
+//	branch: trunk
+//
+// Only the settings present in the file are changed, so cfg can hold defaults beforehand and be
+// overridden with settings of a higher precedence afterwards. Unknown keys are an error. The path
+// of the file read is returned, or an empty string if the repository has no config file.
+func LoadConfigFile(repoPath string, cfg *GitRepoConfig) (string, error) {
+	root := repoPath
+	if _, workTree, err := resolveGitDir(repoPath); err == nil && workTree != "" {
+		root = workTree
+	}
+
+	for _, name := range ConfigFileNames {
+		path := filepath.Join(root, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		// maps are decoded into, rather than replaced, so a map in the file must start empty
+		types := cfg.ConventionalTypes
+		cfg.ConventionalTypes = nil
+
+		if strings.HasSuffix(name, ".toml") {
+			err = decodeTOMLConfig(data, cfg)
+		} else {
+			err = decodeYAMLConfig(data, cfg)
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading config file %s: %s", path, err)
+		}
+
+		if cfg.ConventionalTypes == nil {
+			cfg.ConventionalTypes = types
+		}
+		return path, nil
+	}
+
+	return "", nil
+}
+
+func decodeYAMLConfig(data []byte, cfg *GitRepoConfig) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+func decodeTOMLConfig(data []byte, cfg *GitRepoConfig) error {
+	md, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return err
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, 0, len(undecoded))
+		for _, k := range undecoded {
+			keys = append(keys, k.String())
+		}
+		return fmt.Errorf("unknown keys: %s", strings.Join(keys, ", "))
+	}
+	return nil
+}
+
+// ValidateConfig reports the configuration errors NewRepo would return before opening the
+// repository, eg: an unknown scheme or invalid pre-release name.
+func ValidateConfig(cfg GitRepoConfig) error {
+	return validateConfig(cfg)
+}
@@ -0,0 +1,120 @@
+package autotag
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected GitRepoConfig
+		file     string
+		err      bool
+	}{
+		{
+			name:     "no config file",
+			expected: GitRepoConfig{Scheme: "autotag", Prefix: true},
+		},
+		{
+			name: "yaml",
+			files: map[string]string{
+				".autotag.yml": "scheme: conventional\nprefix: false\nbranch: trunk\npaths:\n  - services/api\n",
+			},
+			expected: GitRepoConfig{Scheme: "conventional", Prefix: false, Branch: "trunk", Paths: []string{"services/api"}},
+			file:     ".autotag.yml",
+		},
+		{
+			name: "toml",
+			files: map[string]string{
+				".autotag.toml": "scheme = \"conventional\"\npre-release-name = \"rc\"\npre-release-counter = true\n",
+			},
+			expected: GitRepoConfig{Scheme: "conventional", Prefix: true, PreReleaseName: "rc", PreReleaseCounter: true},
+			file:     ".autotag.toml",
+		},
+		{
+			name: "yaml conventional types",
+			files: map[string]string{
+				".autotag.yml": "scheme: conventional\nconventional-types:\n  perf: minor\n  docs: none\n",
+			},
+			expected: GitRepoConfig{Scheme: "conventional", Prefix: true, ConventionalTypes: map[string]string{"perf": "minor", "docs": "none"}},
+			file:     ".autotag.yml",
+		},
+		{
+			name: "toml conventional types",
+			files: map[string]string{
+				".autotag.toml": "scheme = \"conventional\"\n[conventional-types]\nperf = \"minor\"\n",
+			},
+			expected: GitRepoConfig{Scheme: "conventional", Prefix: true, ConventionalTypes: map[string]string{"perf": "minor"}},
+			file:     ".autotag.toml",
+		},
+		{
+			name: "yaml preferred over toml",
+			files: map[string]string{
+				".autotag.yml":  "scheme: conventional\n",
+				".autotag.toml": "scheme = \"test-jira\"\n",
+			},
+			expected: GitRepoConfig{Scheme: "conventional", Prefix: true},
+			file:     ".autotag.yml",
+		},
+		{
+			name:     "empty file",
+			files:    map[string]string{".autotag.yml": ""},
+			expected: GitRepoConfig{Scheme: "autotag", Prefix: true},
+			file:     ".autotag.yml",
+		},
+		{
+			name:  "unknown yaml key",
+			files: map[string]string{".autotag.yml": "schem: conventional\n"},
+			err:   true,
+		},
+		{
+			name:  "unknown toml key",
+			files: map[string]string{".autotag.toml": "schem = \"conventional\"\n"},
+			err:   true,
+		},
+		{
+			name:  "repo path is not configurable",
+			files: map[string]string{".autotag.yml": "RepoPath: /tmp\n"},
+			err:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tc.files {
+				assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+			}
+
+			cfg := GitRepoConfig{Scheme: "autotag", Prefix: true}
+			path, err := LoadConfigFile(dir, &cfg)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg)
+			if tc.file == "" {
+				assert.Equal(t, "", path)
+			} else {
+				assert.Equal(t, filepath.Join(dir, tc.file), path)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileReplacesMaps(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".autotag.yml"), []byte("conventional-types:\n  docs: none\n"), 0o644))
+
+	cfg := GitRepoConfig{ConventionalTypes: map[string]string{"perf": "minor"}}
+	_, err := LoadConfigFile(dir, &cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"docs": "none"}, cfg.ConventionalTypes)
+}
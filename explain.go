@@ -0,0 +1,59 @@
+package autotag
+
+// CommitExplanation describes how a single commit affected the calculated version
+type CommitExplanation struct {
+	// ID is the commit id
+	ID string
+
+	// Subject is the first line of the commit message
+	Subject string
+
+	// Bump is the version bump requested by the commit
+	Bump BumpLevel
+
+	// Rule describes the scheme rule that matched the commit message, eg: "[major] or #major". It
+	// is empty if no rule matched or the scheme doesn't implement RuleScheme.
+	Rule string
+
+	// Version is the version the commit alone would produce from the previous version
+	Version string
+
+	// Decisive is true for the commit that decided the new version: the first commit requesting
+	// the bump that was applied. No commit is decisive when the default patch bump was applied.
+	Decisive bool
+}
+
+// Explain describes how each commit inspected when calculating the new version affected it, in
+// chronological order.
+func (r *GitRepo) Explain() ([]CommitExplanation, error) {
+	explanations := make([]CommitExplanation, 0, len(r.commits))
+	for _, c := range r.commits {
+		e := CommitExplanation{
+			ID:       c.ID.String(),
+			Subject:  c.Summary(),
+			Bump:     c.bump,
+			Decisive: r.bumpCommit != nil && r.bumpCommit.ID.Equal(c.ID),
+		}
+
+		if rs, ok := r.scheme.(RuleScheme); ok {
+			_, e.Rule = rs.Rule(c.Message)
+		}
+
+		// without a bump the commit alone gets a patch release, unless it isn't releasable
+		e.Version = r.currentVersion.String()
+		b := c.bump.bumper()
+		if b == nil && !(c.bump == BumpNone && r.skipUnreleasable) {
+			b = patchBumper
+		}
+		if b != nil {
+			v, err := b.bump(r.currentVersion)
+			if err != nil {
+				return nil, err
+			}
+			e.Version = v.String()
+		}
+
+		explanations = append(explanations, e)
+	}
+	return explanations, nil
+}
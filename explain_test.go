@@ -0,0 +1,85 @@
+package autotag
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert"
+)
+
+func TestExplain(t *testing.T) {
+	type explanation struct {
+		bump     BumpLevel
+		rule     string
+		version  string
+		decisive bool
+	}
+
+	tests := []struct {
+		name     string
+		scheme   string
+		commits  []string
+		expected []explanation
+	}{
+		{
+			name:    "autotag scheme",
+			scheme:  "autotag",
+			commits: []string{"#minor add a feature", "fix a bug", "[major] drop the v1 API", "#major drop the v2 API"},
+			expected: []explanation{
+				{BumpMinor, "[minor] or #minor", "1.1.0", false},
+				{BumpUnspecified, "", "1.0.1", false},
+				{BumpMajor, "[major] or #major", "2.0.0", true},
+				{BumpMajor, "[major] or #major", "2.0.0", false},
+			},
+		},
+		{
+			name:    "conventional scheme",
+			scheme:  "conventional",
+			commits: []string{"feat: add a feature", "fix!: change a default", "fix: a bug\n\nBREAKING CHANGE: it was a feature"},
+			expected: []explanation{
+				{BumpMinor, "type feat", "1.1.0", false},
+				{BumpMajor, "! after type/scope", "2.0.0", true},
+				{BumpMajor, "BREAKING CHANGE: footer", "2.0.0", false},
+			},
+		},
+		{
+			name:    "fallback patch bump has no decisive commit",
+			scheme:  "autotag",
+			commits: []string{"fix a bug", "fix another bug"},
+			expected: []explanation{
+				{BumpUnspecified, "", "1.0.1", false},
+				{BumpUnspecified, "", "1.0.1", false},
+			},
+		},
+		{
+			name:    "schemes without rules",
+			scheme:  "test-jira",
+			commits: []string{"STORY-1 add a feature"},
+			expected: []explanation{
+				{BumpMinor, "", "1.1.0", true},
+			},
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				r := newTestRepo(t, testRepoSetup{
+					scheme:     tc.scheme,
+					initialTag: "v1.0.0",
+					commitList: tc.commits,
+					backend:    backend,
+				})
+				defer cleanupTestRepo(t, gitModuleRepo(t, &r))
+
+				explanations, err := r.Explain()
+				checkFatal(t, err)
+
+				actual := make([]explanation, 0, len(explanations))
+				for _, e := range explanations {
+					actual = append(actual, explanation{e.Bump, e.Rule, e.Version, e.Decisive})
+				}
+				assert.Equal(t, tc.expected, actual)
+			})
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/gogs/git-module"
@@ -109,6 +110,37 @@ func updateReadme(t *testing.T, repo *git.Repository, content string) {
 	makeCommit(repo, content)
 }
 
+// tagObjectType returns the git object type a tag ref points at: "tag" for annotated tags and
+// "commit" for lightweight tags
+func tagObjectType(t *testing.T, r *git.Repository, tag string) string {
+	cmd := exec.Command("git", "cat-file", "-t", "refs/tags/"+tag)
+	cmd.Dir = repoRoot(r)
+	out, err := cmd.Output()
+	checkFatal(t, err)
+	return strings.TrimSpace(string(out))
+}
+
+// tagContents returns the message of an annotated tag
+func tagContents(t *testing.T, r *git.Repository, tag string) string {
+	cmd := exec.Command("git", "tag", "-l", "--format=%(contents)", tag)
+	cmd.Dir = repoRoot(r)
+	out, err := cmd.Output()
+	checkFatal(t, err)
+	return string(out)
+}
+
+// commitFile writes content to a file relative to the repo root, creating parent directories as
+// needed, and commits it with content as the commit message
+func commitFile(t *testing.T, repo *git.Repository, path, content string) {
+	f := filepath.Join(repoRoot(repo), path)
+	err := os.MkdirAll(filepath.Dir(f), 0o755)
+	checkFatal(t, err)
+	err = os.WriteFile(f, []byte(content), 0o644)
+	checkFatal(t, err)
+
+	makeCommit(repo, content)
+}
+
 func repoRoot(r *git.Repository) string {
 	checkPath := r.Path()
 	if filepath.Base(checkPath) == ".git" {
@@ -121,3 +153,10 @@ func repoRoot(r *git.Repository) string {
 	}
 	return p
 }
+
+// gitModuleRepo opens the git directory of r with git-module, for the helpers running git in it
+func gitModuleRepo(t *testing.T, r *GitRepo) *git.Repository {
+	repo, err := git.Open(r.gitDir)
+	checkFatal(t, err)
+	return repo
+}
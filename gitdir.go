@@ -0,0 +1,79 @@
+package autotag
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolveGitDir finds the git directory of the repository containing path the way git does. Each
+// directory from path up to the root of the filesystem is checked for:
+//
+//   - a .git directory, as in a regular clone
+//   - a .git file containing "gitdir: <path>", as in worktrees and submodules
+//   - the directory itself being a git directory, as in bare repositories
+//
+// The work tree is the directory containing .git, or an empty string for a bare repository.
+func resolveGitDir(path string) (gitDir, workTree string, err error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		dotGit := filepath.Join(dir, ".git")
+		fi, err := os.Stat(dotGit)
+		switch {
+		case err == nil && fi.IsDir() && isGitDir(dotGit):
+			return dotGit, dir, nil
+		case err == nil && !fi.IsDir():
+			target, err := readGitDirFile(dotGit)
+			if err != nil {
+				return "", "", err
+			}
+			return target, dir, nil
+		case isGitDir(dir):
+			return dir, "", nil
+		}
+
+		if filepath.Dir(dir) == dir {
+			return "", "", fmt.Errorf("not a git repository (or any of the parent directories): %s", abs)
+		}
+	}
+}
+
+// readGitDirFile returns the git directory a .git file points to
+func readGitDirFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", fmt.Errorf("invalid gitfile format: %s", path)
+	}
+
+	target := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	if !isGitDir(target) {
+		return "", fmt.Errorf("not a git repository: %s (referenced by %s)", target, path)
+	}
+	return filepath.Clean(target), nil
+}
+
+// isGitDir reports whether dir looks like a git directory: it has a HEAD and either an object
+// store or, for linked worktrees, a commondir file pointing to the main repository's.
+func isGitDir(dir string) bool {
+	if _, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil {
+		return false
+	}
+	if fi, err := os.Stat(filepath.Join(dir, "objects")); err == nil && fi.IsDir() {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(dir, "commondir"))
+	return err == nil
+}
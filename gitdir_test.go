@@ -0,0 +1,92 @@
+package autotag
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert"
+	"github.com/gogs/git-module"
+)
+
+func TestResolveGitDir(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+
+		seedTestRepo(t, "v1.0.0", repo)
+		commitFile(t, repo, "services/api/main.go", "package main\n")
+		root := repoRoot(repo)
+
+		bare := filepath.Join(t.TempDir(), "bare.git")
+		runGit(t, root, "clone", "--bare", root, bare)
+
+		worktree := filepath.Join(t.TempDir(), "worktree")
+		runGit(t, root, "worktree", "add", "-b", "feature", worktree)
+
+		super := filepath.Join(t.TempDir(), "super")
+		runGit(t, t.TempDir(), "init", super)
+		runGit(t, super, "-c", "protocol.file.allow=always", "submodule", "add", root, "sub")
+
+		tests := []struct {
+			name             string
+			path             string
+			expectedGitDir   string
+			expectedWorkTree string
+		}{
+			{
+				name:             "root of the working tree",
+				path:             root,
+				expectedGitDir:   filepath.Join(root, ".git"),
+				expectedWorkTree: root,
+			},
+			{
+				name:             "subdirectory",
+				path:             filepath.Join(root, "services", "api"),
+				expectedGitDir:   filepath.Join(root, ".git"),
+				expectedWorkTree: root,
+			},
+			{
+				name:           "bare repository",
+				path:           bare,
+				expectedGitDir: bare,
+			},
+			{
+				name:             "linked worktree",
+				path:             worktree,
+				expectedGitDir:   filepath.Join(root, ".git", "worktrees", "worktree"),
+				expectedWorkTree: worktree,
+			},
+			{
+				name:             "submodule",
+				path:             filepath.Join(super, "sub"),
+				expectedGitDir:   filepath.Join(super, ".git", "modules", "sub"),
+				expectedWorkTree: filepath.Join(super, "sub"),
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				gitDir, workTree, err := resolveGitDir(tc.path)
+				checkFatal(t, err)
+				assert.Equal(t, tc.expectedGitDir, gitDir)
+				assert.Equal(t, tc.expectedWorkTree, workTree)
+
+				r, err := NewRepo(GitRepoConfig{RepoPath: tc.path, Branch: "master", Backend: backend})
+				checkFatal(t, err)
+				assert.Equal(t, "1.0.1", r.LatestVersion())
+			})
+		}
+	})
+}
+
+func TestResolveGitDirErrors(t *testing.T) {
+	_, _, err := resolveGitDir(t.TempDir())
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".git"), []byte("gitdir: ../missing\n"), 0o644))
+	_, _, err = resolveGitDir(dir)
+	assert.Error(t, err)
+}
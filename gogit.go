@@ -0,0 +1,380 @@
+package autotag
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/gogs/git-module"
+)
+
+// goGitRepository is the repository backend reading and writing the git directory with go-git,
+// without running the git CLI
+type goGitRepository struct {
+	repo    *gogit.Repository
+	storage *filesystem.Storage
+	packs   string // pack files indexed by storage, to notice packs added by the git CLI
+}
+
+func openGoGitRepository(gitDir string) (repository, error) {
+	repo, err := gogit.PlainOpenWithOptions(gitDir, &gogit.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %s", gitDir, err)
+	}
+
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, fmt.Errorf("error opening %s: unsupported storage %T", gitDir, repo.Storer)
+	}
+	return &goGitRepository{repo: repo, storage: storage}, nil
+}
+
+// refresh reindexes the pack files when they changed since the last call, since go-git indexes
+// them once but autotag fetches with the git CLI, eg: when deepening a shallow clone
+func (g *goGitRepository) refresh() error {
+	hashes, err := g.storage.ObjectPacks()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(hashes))
+	for i, h := range hashes {
+		names[i] = h.String()
+	}
+	sort.Strings(names)
+
+	if packs := strings.Join(names, ","); packs != g.packs {
+		g.storage.Reindex()
+		g.packs = packs
+	}
+	return nil
+}
+
+func (g *goGitRepository) Tags(filter tagFilter) ([]string, error) {
+	refs, err := g.TagRefs(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, len(refs))
+	for i, ref := range refs {
+		tags[i] = ref.name
+	}
+	return tags, nil
+}
+
+func (g *goGitRepository) TagRefs(filter tagFilter) ([]tagRef, error) {
+	if err := g.refresh(); err != nil {
+		return nil, err
+	}
+
+	var pointsAt *plumbing.Hash
+	if filter.pointsAt != "" {
+		h, err := g.resolve(filter.pointsAt)
+		if err != nil {
+			return nil, err
+		}
+		pointsAt = &h
+	}
+
+	var merged map[plumbing.Hash]bool
+	if filter.merged != "" {
+		h, err := g.resolve(filter.merged)
+		if err != nil {
+			return nil, err
+		}
+		if merged, err = g.reachable([]plumbing.Hash{h}, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	refs, err := g.repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []tagRef
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		tag := tagRef{name: ref.Name().Short()}
+		if filter.pattern != "" {
+			if ok, _ := path.Match(filter.pattern, tag.name); !ok {
+				return nil
+			}
+		}
+
+		target, err := g.peel(ref.Hash())
+		if err == nil {
+			tag.commit = target.String()
+		} else if pointsAt != nil || merged != nil {
+			return nil // not a tag of a commit
+		}
+
+		if pointsAt != nil && target != *pointsAt && ref.Hash() != *pointsAt {
+			return nil
+		}
+		if merged != nil && !merged[target] {
+			return nil
+		}
+
+		tags = append(tags, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].name < tags[j].name })
+	return tags, nil
+}
+
+func (g *goGitRepository) CommitByRevision(rev string) (*git.Commit, error) {
+	if err := g.refresh(); err != nil {
+		return nil, err
+	}
+
+	h, err := g.resolve(rev)
+	if err != nil {
+		return nil, err
+	}
+	return g.commit(h)
+}
+
+func (g *goGitRepository) BranchCommit(branch string) (*git.Commit, error) {
+	if err := g.refresh(); err != nil {
+		return nil, err
+	}
+
+	ref, err := g.repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return nil, git.ErrRevisionNotExist
+	}
+	return g.commit(ref.Hash())
+}
+
+func (g *goGitRepository) RevList(refspecs []string) ([]*git.Commit, error) {
+	if err := g.refresh(); err != nil {
+		return nil, err
+	}
+
+	var include, exclude []plumbing.Hash
+	for _, spec := range refspecs {
+		from, to, isRange := strings.Cut(spec, "..")
+		if !isRange {
+			to = spec
+		} else {
+			h, err := g.resolve(from)
+			if err != nil {
+				return nil, err
+			}
+			exclude = append(exclude, h)
+		}
+
+		h, err := g.resolve(to)
+		if err != nil {
+			return nil, err
+		}
+		include = append(include, h)
+	}
+
+	excluded, err := g.reachable(exclude, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*git.Commit
+	_, err = g.reachable(include, func(c *object.Commit) bool {
+		if excluded[c.Hash] {
+			return false
+		}
+		commits = append(commits, convertCommit(c))
+		return true
+	})
+	return commits, err
+}
+
+func (g *goGitRepository) CreateTag(name, rev string, opts git.CreateTagOptions) error {
+	if err := g.refresh(); err != nil {
+		return err
+	}
+
+	h, err := g.resolve(rev)
+	if err != nil {
+		return err
+	}
+
+	var tagOpts *gogit.CreateTagOptions
+	if opts.Annotated {
+		tagOpts = &gogit.CreateTagOptions{Message: opts.Message}
+		if opts.Author != nil {
+			tagOpts.Tagger = &object.Signature{Name: opts.Author.Name, Email: opts.Author.Email, When: opts.Author.When}
+		}
+	}
+
+	_, err = g.repo.CreateTag(name, h, tagOpts)
+	return err
+}
+
+func (g *goGitRepository) DeleteTag(name string) error {
+	return g.repo.DeleteTag(name)
+}
+
+func (g *goGitRepository) Branches() ([]string, error) {
+	refs, err := g.repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		branches = append(branches, ref.Name().Short())
+		return nil
+	})
+	return branches, err
+}
+
+// resolve returns the commit a revision points to
+func (g *goGitRepository) resolve(rev string) (plumbing.Hash, error) {
+	h, err := g.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) || errors.Is(err, plumbing.ErrObjectNotFound) {
+			return plumbing.ZeroHash, git.ErrRevisionNotExist
+		}
+		return plumbing.ZeroHash, err
+	}
+	return *h, nil
+}
+
+// peel returns the commit a tag ref points to, following annotated tags
+func (g *goGitRepository) peel(h plumbing.Hash) (plumbing.Hash, error) {
+	for {
+		tag, err := g.repo.TagObject(h)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			break
+		}
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		h = tag.Target
+	}
+
+	if _, err := g.repo.CommitObject(h); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return h, nil
+}
+
+func (g *goGitRepository) commit(h plumbing.Hash) (*git.Commit, error) {
+	c, err := g.repo.CommitObject(h)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil, git.ErrRevisionNotExist
+		}
+		return nil, err
+	}
+	return convertCommit(c), nil
+}
+
+// reachable walks the commits reachable from heads newest first, like git rev-list, and returns
+// the set of commits visited. If visit is given, the walk doesn't continue past the commits it
+// returns false for. Parents missing from a shallow clone end the walk.
+func (g *goGitRepository) reachable(heads []plumbing.Hash, visit func(*object.Commit) bool) (map[plumbing.Hash]bool, error) {
+	shallow, err := g.storage.Shallow()
+	if err != nil {
+		return nil, err
+	}
+	boundary := make(map[plumbing.Hash]bool, len(shallow))
+	for _, h := range shallow {
+		boundary[h] = true
+	}
+
+	seen := make(map[plumbing.Hash]bool)
+	queue := &commitQueue{}
+	push := func(h plumbing.Hash) error {
+		if seen[h] {
+			return nil
+		}
+		seen[h] = true
+
+		c, err := g.repo.CommitObject(h)
+		if err != nil {
+			return err
+		}
+		heap.Push(queue, c)
+		return nil
+	}
+
+	for _, h := range heads {
+		if err := push(h); err != nil {
+			return nil, err
+		}
+	}
+
+	for queue.Len() > 0 {
+		c := heap.Pop(queue).(*object.Commit)
+		if visit != nil && !visit(c) {
+			continue
+		}
+		if boundary[c.Hash] {
+			continue
+		}
+		for _, p := range c.ParentHashes {
+			if err := push(p); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return seen, nil
+}
+
+// commitQueue orders commits by committer date, newest first, and then in the order they were
+// queued, the way git rev-list does by default
+type commitQueue struct {
+	commits []*object.Commit
+	order   []int
+	next    int
+}
+
+func (q *commitQueue) Len() int { return len(q.commits) }
+
+func (q *commitQueue) Less(i, j int) bool {
+	ti, tj := q.commits[i].Committer.When, q.commits[j].Committer.When
+	if !ti.Equal(tj) {
+		return ti.After(tj)
+	}
+	return q.order[i] < q.order[j]
+}
+
+func (q *commitQueue) Swap(i, j int) {
+	q.commits[i], q.commits[j] = q.commits[j], q.commits[i]
+	q.order[i], q.order[j] = q.order[j], q.order[i]
+}
+
+func (q *commitQueue) Push(x interface{}) {
+	q.commits = append(q.commits, x.(*object.Commit))
+	q.order = append(q.order, q.next)
+	q.next++
+}
+
+func (q *commitQueue) Pop() interface{} {
+	n := len(q.commits) - 1
+	c := q.commits[n]
+	q.commits, q.order = q.commits[:n], q.order[:n]
+	return c
+}
+
+// convertCommit returns the git-module commit for a go-git commit, with the fields autotag uses
+func convertCommit(c *object.Commit) *git.Commit {
+	return &git.Commit{
+		ID:        git.MustIDFromString(c.Hash.String()),
+		Author:    &git.Signature{Name: c.Author.Name, Email: c.Author.Email, When: c.Author.When},
+		Committer: &git.Signature{Name: c.Committer.Name, Email: c.Committer.Email, When: c.Committer.When},
+		Message:   c.Message,
+	}
+}
@@ -0,0 +1,75 @@
+package autotag
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// maintenanceLine is the version line declared by the name of a maintenance branch, eg: 1.4.x for
+// a branch named release/1.4
+type maintenanceLine struct {
+	branch string
+	major  int
+	minor  int // -1 when the branch only declares a major version
+}
+
+// maintenancePatternRex converts a maintenance branch pattern into a regular expression. It returns
+// an error if the pattern doesn't contain a {major} placeholder.
+func maintenancePatternRex(pattern string) (*regexp.Regexp, error) {
+	if !strings.Contains(pattern, "{major}") {
+		return nil, fmt.Errorf("maintenance branch pattern '%s' must contain {major}", pattern)
+	}
+
+	rex := regexp.QuoteMeta(pattern)
+	rex = strings.Replace(rex, `\{major\}`, `(?P<major>\d+)`, 1)
+	rex = strings.Replace(rex, `\{minor\}`, `(?P<minor>\d+)`, 1)
+	return regexp.Compile("^" + rex + "$")
+}
+
+// parseMaintenanceLine returns the version line of branch according to the first matching pattern,
+// or nil if branch isn't a maintenance branch.
+func parseMaintenanceLine(patterns []string, branch string) (*maintenanceLine, error) {
+	for _, p := range patterns {
+		rex, err := maintenancePatternRex(p)
+		if err != nil {
+			return nil, err
+		}
+
+		if !rex.MatchString(branch) {
+			continue
+		}
+
+		matches := findNamedMatches(rex, branch)
+		line := &maintenanceLine{branch: branch, minor: -1}
+		if line.major, err = strconv.Atoi(matches["major"]); err != nil {
+			return nil, err
+		}
+		if m, ok := matches["minor"]; ok {
+			if line.minor, err = strconv.Atoi(m); err != nil {
+				return nil, err
+			}
+		}
+		return line, nil
+	}
+	return nil, nil
+}
+
+// contains reports whether v belongs to the version line
+func (l *maintenanceLine) contains(v *version.Version) bool {
+	segments := v.Segments()
+	if segments[0] != l.major {
+		return false
+	}
+	return l.minor < 0 || segments[1] == l.minor
+}
+
+func (l *maintenanceLine) String() string {
+	if l.minor < 0 {
+		return fmt.Sprintf("%d.x", l.major)
+	}
+	return fmt.Sprintf("%d.%d.x", l.major, l.minor)
+}
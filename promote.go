@@ -0,0 +1,85 @@
+package autotag
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// Promote makes the stable counterpart of the newest pre-release tag reachable from the branch the
+// new version, eg: v1.2.3 for v1.2.3-rc.2. The new version targets the commit of the pre-release tag
+// rather than the head of the branch, so exactly what was tested is released even if the branch has
+// moved on since. Call AutoTag to create the tag.
+//
+// When PreReleaseName is set only pre-releases with that name are considered. An error is returned
+// if no pre-release tag is found, or if a stable tag for the version already exists.
+func (r *GitRepo) Promote() error {
+	tags, err := r.versionTags(r.allTags)
+	if err != nil {
+		return err
+	}
+
+	var preRelease *versionTag
+	for i, tag := range tags {
+		if !r.isPromotable(tag.version) {
+			continue
+		}
+		if preRelease == nil || tag.version.GreaterThan(preRelease.version) {
+			preRelease = &tags[i]
+		}
+	}
+
+	if preRelease == nil {
+		return fmt.Errorf("no pre-release version tags found to promote")
+	}
+
+	// a stable tag anywhere in the repository blocks the promotion, not only reachable ones
+	stable := preRelease.version.Core()
+	all, err := r.versionTags(true)
+	if err != nil {
+		return err
+	}
+	for _, tag := range all {
+		if len(tag.version.Prerelease()) == 0 && tag.version.Core().Equal(stable) {
+			return fmt.Errorf("refusing to promote %s: stable tag %s already exists", preRelease.name, tag.name)
+		}
+	}
+
+	commit, err := r.repo.CommitByRevision(preRelease.revision())
+	if err != nil {
+		return fmt.Errorf("error reading commit '%s':  %s", preRelease.name, err)
+	}
+
+	r.currentVersion = preRelease.version
+	r.currentTag = commit
+	r.currentTagName = preRelease.name
+	r.branchID = commit.ID.String()
+	r.newVersion = stable
+	r.bump = BumpUnspecified
+	r.bumpCommit = nil
+	r.commits = nil
+	r.promoted = true
+	r.noRelease = false
+	r.existingTag = ""
+
+	// append optional build metadata
+	if r.buildMetadata != "" {
+		if r.newVersion, err = version.NewVersion(fmt.Sprintf("%s+%s", r.newVersion.String(), r.buildMetadata)); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Promoting %s to %s on %s", preRelease.name, r.tagName(), r.branchID)
+	return nil
+}
+
+// isPromotable reports whether v is a pre-release matching the configured pre-release name
+func (r *GitRepo) isPromotable(v *version.Version) bool {
+	pre := v.Prerelease()
+	if len(pre) == 0 {
+		return false
+	}
+	return r.preReleaseName == "" || pre == r.preReleaseName || strings.HasPrefix(pre, r.preReleaseName+".")
+}
@@ -0,0 +1,122 @@
+package autotag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert"
+	"github.com/gogs/git-module"
+)
+
+func TestPromote(t *testing.T) {
+	tests := []struct {
+		name           string
+		preReleaseName string
+		extraTags      []string
+		shouldErr      bool
+		expectedTag    string
+		expectedCommit string // tag the expected commit carries
+	}{
+		{
+			name:           "newest pre-release is promoted on its commit",
+			expectedTag:    "v1.1.0",
+			expectedCommit: "v1.1.0-rc.2",
+		},
+		{
+			name:           "pre-release name filter",
+			preReleaseName: "rc",
+			extraTags:      []string{"v1.2.0-beta.1"},
+			expectedTag:    "v1.1.0",
+			expectedCommit: "v1.1.0-rc.2",
+		},
+		{
+			name:           "newest pre-release of any name",
+			extraTags:      []string{"v1.2.0-beta.1"},
+			expectedTag:    "v1.2.0",
+			expectedCommit: "v1.2.0-beta.1",
+		},
+		{
+			name:           "no matching pre-release",
+			preReleaseName: "alpha",
+			shouldErr:      true,
+		},
+		{
+			name:      "stable version already exists",
+			extraTags: []string{"v1.1.0"},
+			shouldErr: true,
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tr := createTestRepo(t, "master")
+				repo, err := git.Open(tr)
+				checkFatal(t, err)
+
+				seedTestRepo(t, "v1.0.0", repo)
+				updateReadme(t, repo, "#minor add a feature")
+				makeTag(repo, "v1.1.0-rc.1")
+				updateReadme(t, repo, "fix the feature")
+				makeTag(repo, "v1.1.0-rc.2")
+
+				// the branch moves on after QA approved rc.2
+				updateReadme(t, repo, "unreleased work")
+				for _, tag := range tc.extraTags {
+					makeTag(repo, tag)
+				}
+
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:       repo.Path(),
+					Branch:         "master",
+					PreReleaseName: tc.preReleaseName,
+					Prefix:         true,
+					AnnotatedTag:   true,
+					Backend:        backend,
+				})
+				checkFatal(t, err)
+
+				err = r.Promote()
+				if tc.shouldErr {
+					assert.Error(t, err)
+					return
+				}
+				assert.NoError(t, err)
+
+				err = r.AutoTag()
+				assert.NoError(t, err)
+				assert.Equal(t, runGit(t, tr, "rev-parse", tc.expectedCommit+"^{commit}"), runGit(t, tr, "rev-parse", tc.expectedTag+"^{commit}"))
+				assert.Equal(t, "Release "+tc.expectedTag, strings.TrimSpace(tagContents(t, repo, tc.expectedTag)))
+			})
+		}
+	})
+}
+
+func TestPromoteFirstReleaseCandidate(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "master")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+
+		// the only tag is the first release candidate, there is no stable version yet
+		seedTestRepo(t, "v1.0.0-rc.1", repo)
+		updateReadme(t, repo, "unreleased work")
+
+		_, err = NewRepo(GitRepoConfig{RepoPath: repo.Path(), Branch: "master", Prefix: true, Backend: backend})
+		assert.Error(t, err)
+
+		r, err := NewRepo(GitRepoConfig{
+			RepoPath: repo.Path(),
+			Branch:   "master",
+			Prefix:   true,
+			Promote:  true,
+			Backend:  backend,
+		})
+		checkFatal(t, err)
+		assert.Equal(t, "1.0.0", r.LatestVersion())
+
+		err = r.AutoTag()
+		assert.NoError(t, err)
+		assert.Equal(t, runGit(t, tr, "rev-parse", "v1.0.0-rc.1^{commit}"), runGit(t, tr, "rev-parse", "v1.0.0^{commit}"))
+	})
+}
@@ -0,0 +1,142 @@
+package autotag
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gogs/git-module"
+	"github.com/hashicorp/go-version"
+)
+
+// pushNewVersion pushes the new version tag to the push remote. When the remote already has the
+// tag on a different commit the local tag is discarded, the remote tags are fetched and the version
+// is recalculated before trying again, up to pushRetries times. Promoted versions are not retried.
+func (r *GitRepo) pushNewVersion() error {
+	for attempt := 0; ; attempt++ {
+		tagName := r.tagName()
+
+		log.Printf("Pushing Tag %s to %s", tagName, r.pushRemote)
+		pushErr := git.Push(r.remoteDir(), r.pushRemote, "refs/tags/"+tagName)
+		if pushErr == nil {
+			return nil
+		}
+
+		remoteID, err := r.remoteTagCommitID(tagName)
+		if err != nil {
+			return fmt.Errorf("error pushing tag '%s': %s", tagName, pushErr)
+		}
+
+		switch remoteID {
+		case "":
+			// the remote doesn't have the tag, so the push failed for some other reason
+			return fmt.Errorf("error pushing tag '%s': %s", tagName, pushErr)
+		case r.branchID:
+			log.Printf("remote %s already has tag %s on %s", r.pushRemote, tagName, r.branchID)
+			return nil
+		}
+
+		log.Printf("remote %s has tag %s on a different commit (%s)", r.pushRemote, tagName, remoteID)
+		// a promoted version targets a fixed commit and an existing tag was already released, so
+		// neither can be recalculated
+		if r.promoted || r.existingTag != "" || attempt >= r.pushRetries {
+			return fmt.Errorf("error pushing tag '%s': remote %s has it on commit %s; gave up after %d retries", tagName, r.pushRemote, remoteID, r.pushRetries)
+		}
+
+		if err := r.repo.DeleteTag(tagName); err != nil {
+			return fmt.Errorf("error deleting local tag '%s': %s", tagName, err)
+		}
+
+		if err := r.refreshTags(); err != nil {
+			return err
+		}
+		if r.noRelease {
+			// the other tag already released every commit
+			return ErrNoRelease
+		}
+
+		if err := r.tagNewVersion(); err != nil {
+			return err
+		}
+	}
+}
+
+// remoteTagCommitID returns the commit id a tag points to on the push remote, or an empty string if
+// the remote doesn't have the tag.
+func (r *GitRepo) remoteTagCommitID(tagName string) (string, error) {
+	ref := "refs/tags/" + tagName
+	out, err := git.NewCommand("ls-remote", "--tags", r.pushRemote, ref, ref+"^{}").RunInDir(r.remoteDir())
+	if err != nil {
+		return "", fmt.Errorf("error listing tags on remote %s: %s", r.pushRemote, err)
+	}
+
+	// annotated tags are listed twice, the peeled `^{}` entry holds the commit the tag points to
+	id := ""
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if fields[1] == ref+"^{}" || id == "" {
+			id = fields[0]
+		}
+	}
+	return id, nil
+}
+
+// remoteDir returns the directory git commands talking to remotes run in. It is the work tree when
+// there is one, since remotes can be configured with paths relative to it, eg: ../remote.git.
+func (r *GitRepo) remoteDir() string {
+	if r.workTree != "" {
+		return r.workTree
+	}
+	return r.gitDir
+}
+
+// refreshTags fetches the tags from the push remote and recalculates the new version. The conflicting
+// tag usually points to a commit that isn't reachable from the branch, so it is ignored by
+// parseTags; versions whose tag already exists are skipped by bumping from them instead, which makes
+// the existing tag the previous release.
+func (r *GitRepo) refreshTags() error {
+	log.Printf("Fetching tags from %s", r.pushRemote)
+	if _, err := git.NewCommand("fetch", "--tags", r.pushRemote).RunInDir(r.remoteDir()); err != nil {
+		return fmt.Errorf("error fetching tags from %s: %s", r.pushRemote, err)
+	}
+
+	if err := r.parseTags(); err != nil {
+		return err
+	}
+	if err := r.calcVersion(); err != nil {
+		return err
+	}
+
+	for !r.noRelease && r.existingTag == "" {
+		exists, err := r.tagExists(r.tagName())
+		if err != nil || !exists {
+			return err
+		}
+
+		name := r.tagName()
+		log.Printf("tag %s already exists, bumping from it", name)
+		c, err := r.repo.CommitByRevision(name)
+		if err != nil {
+			return fmt.Errorf("error loading commit of tag '%s': %s", name, err)
+		}
+		r.currentVersion = version.Must(version.NewVersion(r.newVersion.Core().String()))
+		r.currentTag = c
+		r.currentTagName = name
+		if err := r.calcVersion(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagExists reports whether the repository has a tag with the given name, reachable or not
+func (r *GitRepo) tagExists(name string) (bool, error) {
+	tags, err := r.repo.Tags(tagFilter{pattern: name})
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch tags: %s", err.Error())
+	}
+	return containsString(tags, name), nil
+}
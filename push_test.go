@@ -0,0 +1,151 @@
+package autotag
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert"
+	"github.com/gogs/git-module"
+)
+
+// runGit runs a git command in dir and fails the test if it errors
+func runGit(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %s: %s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newTestRemote creates a bare clone of the test repo and adds it as the "origin" remote. It
+// returns the path to the bare repository.
+func newTestRemote(t *testing.T, repo *git.Repository) string {
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, repoRoot(repo), "clone", "--bare", repoRoot(repo), remote)
+	runGit(t, repoRoot(repo), "remote", "add", "origin", remote)
+	return remote
+}
+
+func TestAutoTagPush(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "master")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+		seedTestRepo(t, "v1.0.0", repo)
+
+		remote := newTestRemote(t, repo)
+		updateReadme(t, repo, "#minor add a feature")
+
+		r, err := NewRepo(GitRepoConfig{
+			RepoPath:   repo.Path(),
+			Branch:     "master",
+			Prefix:     true,
+			PushRemote: "origin",
+			Backend:    backend,
+		})
+		checkFatal(t, err)
+
+		err = r.AutoTag()
+		assert.NoError(t, err)
+		assert.Equal(t, runGit(t, tr, "rev-parse", "master"), runGit(t, remote, "rev-parse", "v1.1.0^{commit}"))
+	})
+}
+
+func TestAutoTagPushRelativeRemote(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "master")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+		seedTestRepo(t, "v1.0.0", repo)
+
+		// a remote path is relative to the work tree, not the git directory
+		remote := filepath.Join(filepath.Dir(tr), "remote.git")
+		runGit(t, tr, "clone", "--bare", tr, remote)
+		runGit(t, tr, "remote", "add", "origin", "../remote.git")
+		updateReadme(t, repo, "#minor add a feature")
+
+		r, err := NewRepo(GitRepoConfig{
+			RepoPath:   tr,
+			Branch:     "master",
+			Prefix:     true,
+			PushRemote: "origin",
+			Backend:    backend,
+		})
+		checkFatal(t, err)
+
+		err = r.AutoTag()
+		assert.NoError(t, err)
+		assert.Equal(t, runGit(t, tr, "rev-parse", "master"), runGit(t, remote, "rev-parse", "v1.1.0^{commit}"))
+	})
+}
+
+func TestAutoTagPushConflict(t *testing.T) {
+	tests := []struct {
+		name        string
+		retries     int
+		shouldErr   bool
+		expectedTag string
+	}{
+		{
+			name:        "conflicting tag is recalculated",
+			retries:     3,
+			expectedTag: "v1.0.2",
+		},
+		{
+			name:      "conflict after retries are exhausted",
+			retries:   0,
+			shouldErr: true,
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				tr := createTestRepo(t, "master")
+				repo, err := git.Open(tr)
+				checkFatal(t, err)
+				seedTestRepo(t, "v1.0.0", repo)
+
+				remote := newTestRemote(t, repo)
+
+				// another pipeline releases v1.0.1 from a different commit
+				other := filepath.Join(t.TempDir(), "other")
+				runGit(t, filepath.Dir(other), "clone", remote, other)
+				runGit(t, other, "commit", "--allow-empty", "-m", "a concurrent change")
+				runGit(t, other, "tag", "v1.0.1")
+				runGit(t, other, "push", "origin", "v1.0.1")
+
+				updateReadme(t, repo, "fix a bug")
+
+				r, err := NewRepo(GitRepoConfig{
+					RepoPath:    repo.Path(),
+					Branch:      "master",
+					Prefix:      true,
+					PushRemote:  "origin",
+					PushRetries: tc.retries,
+					Backend:     backend,
+				})
+				checkFatal(t, err)
+				assert.Equal(t, "1.0.1", r.LatestVersion())
+
+				err = r.AutoTag()
+				if tc.shouldErr {
+					assert.Error(t, err)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, runGit(t, tr, "rev-parse", "master"), runGit(t, remote, "rev-parse", tc.expectedTag+"^{commit}"))
+
+				// the other pipeline's tag is the previous release
+				rel := r.Release()
+				assert.Equal(t, "v1.0.1", rel.PreviousTag)
+				assert.Equal(t, "1.0.1", rel.PreviousVersion)
+				assert.Equal(t, runGit(t, other, "rev-parse", "v1.0.1^{commit}"), rel.PreviousCommit)
+			})
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package autotag
+
+// Release describes the release calculated for a repository: the version it starts from, the new
+// version and the commits that were inspected to calculate it.
+type Release struct {
+	// PreviousTag is the name of the tag the new version was calculated from, eg: v1.2.2. It is
+	// empty, as are PreviousVersion and PreviousCommit, for the first release of a repository.
+	PreviousTag string `json:"previous_tag"`
+
+	// PreviousVersion is the version of PreviousTag, eg: 1.2.2
+	PreviousVersion string `json:"previous_version"`
+
+	// PreviousCommit is the id of the commit PreviousTag points to
+	PreviousCommit string `json:"previous_commit"`
+
+	// Tag is the name of the tag for the new version, eg: v1.2.3
+	Tag string `json:"tag"`
+
+	// Version is the new version, eg: 1.2.3
+	Version string `json:"version"`
+
+	// Commit is the id of the commit the new tag is applied to
+	Commit string `json:"commit"`
+
+	// Bump is the bump applied to the previous version: "major", "minor" or "patch". It is empty
+	// when a pre-release was promoted or the initial version was used as is.
+	Bump string `json:"bump"`
+
+	// Commits are the commits inspected to calculate the new version, in chronological order
+	Commits []ReleaseCommit `json:"commits"`
+
+	// NoRelease is true when GitRepoConfig.SkipUnreleasable is set and no commit warrants a release.
+	// Tag and Version are then those of the previous tag.
+	NoRelease bool `json:"no_release"`
+
+	// Existing is true when Commit already had the version tag Tag, which AutoTag doesn't recreate.
+	Existing bool `json:"existing"`
+}
+
+// ReleaseCommit describes a commit inspected when calculating a release
+type ReleaseCommit struct {
+	// ID is the commit id
+	ID string `json:"id"`
+
+	// Subject is the first line of the commit message
+	Subject string `json:"subject"`
+
+	// Bump is the bump requested by the commit message according to the scheme: "major", "minor",
+	// "patch", "none" or "unspecified"
+	Bump string `json:"bump"`
+}
+
+// Release returns a description of the calculated release
+func (r *GitRepo) Release() Release {
+	rel := Release{
+		Tag:       r.tagName(),
+		Version:   r.newVersion.String(),
+		Commit:    r.branchID,
+		Commits:   make([]ReleaseCommit, 0, len(r.commits)),
+		NoRelease: r.noRelease,
+		Existing:  r.existingTag != "",
+	}
+	if r.currentTag != nil {
+		rel.PreviousTag = r.currentTagName
+		rel.PreviousVersion = r.currentVersion.String()
+		rel.PreviousCommit = r.currentTag.ID.String()
+	}
+	if r.bump != BumpUnspecified {
+		rel.Bump = r.bump.String()
+	}
+
+	for _, c := range r.commits {
+		rel.Commits = append(rel.Commits, ReleaseCommit{
+			ID:      c.ID.String(),
+			Subject: c.Summary(),
+			Bump:    c.bump.String(),
+		})
+	}
+	return rel
+}
@@ -0,0 +1,33 @@
+package autotag
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert"
+)
+
+func TestRelease(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		r := newTestRepo(t, testRepoSetup{
+			initialTag: "v1.0.0",
+			commitList: []string{"#minor add a feature", "fix a bug\n\nwith a body"},
+			backend:    backend,
+		})
+		defer cleanupTestRepo(t, gitModuleRepo(t, &r))
+
+		rel := r.Release()
+		root := repoRoot(gitModuleRepo(t, &r))
+
+		assert.Equal(t, "v1.0.0", rel.PreviousTag)
+		assert.Equal(t, "1.0.0", rel.PreviousVersion)
+		assert.Equal(t, runGit(t, root, "rev-parse", "v1.0.0"), rel.PreviousCommit)
+		assert.Equal(t, "v1.1.0", rel.Tag)
+		assert.Equal(t, "1.1.0", rel.Version)
+		assert.Equal(t, runGit(t, root, "rev-parse", "master"), rel.Commit)
+		assert.Equal(t, "minor", rel.Bump)
+		assert.Equal(t, []ReleaseCommit{
+			{ID: runGit(t, root, "rev-parse", "master~1"), Subject: "#minor add a feature", Bump: "minor"},
+			{ID: runGit(t, root, "rev-parse", "master"), Subject: "fix a bug", Bump: "unspecified"},
+		}, rel.Commits)
+	})
+}
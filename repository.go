@@ -0,0 +1,180 @@
+package autotag
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gogs/git-module"
+)
+
+// Names of the backends GitRepoConfig.Backend can select
+const (
+	backendGit   = "git"
+	backendGoGit = "go-git"
+
+	// defaultBackend is the backend used when GitRepoConfig.Backend is empty
+	defaultBackend = backendGit
+)
+
+// backends opens the repository in a git directory with each backend
+var backends = map[string]func(gitDir string) (repository, error){
+	backendGit:   openCLIRepository,
+	backendGoGit: openGoGitRepository,
+}
+
+// repository is the subset of git operations autotag reads the history and writes tags with.
+// Pushing, signing and path filtering always run the git CLI.
+type repository interface {
+	// Tags returns the names of the tags matching the filter
+	Tags(filter tagFilter) ([]string, error)
+
+	// TagRefs returns the tags matching the filter together with the commits they point to, read
+	// in a single pass over the tag refs
+	TagRefs(filter tagFilter) ([]tagRef, error)
+
+	// CommitByRevision returns the commit a revision, eg: a tag, branch or commit id, points to. It
+	// returns git.ErrRevisionNotExist if the revision can't be resolved.
+	CommitByRevision(rev string) (*git.Commit, error)
+
+	// BranchCommit returns the latest commit of a local branch
+	BranchCommit(branch string) (*git.Commit, error)
+
+	// RevList returns the commits reachable from refspecs, newest first, like git rev-list. A
+	// refspec is a revision or a range of revisions, eg: v1.0.0..main.
+	RevList(refspecs []string) ([]*git.Commit, error)
+
+	// CreateTag creates a lightweight or annotated tag pointing at rev
+	CreateTag(name, rev string, opts git.CreateTagOptions) error
+
+	// DeleteTag deletes a tag
+	DeleteTag(name string) error
+
+	// Branches returns the names of the local branches
+	Branches() ([]string, error)
+}
+
+// tagFilter narrows down the tags returned by repository.Tags. Empty fields match any tag.
+type tagFilter struct {
+	pattern  string // glob the tag name matches, eg: v1.2.3-rc.*
+	merged   string // revision the tagged commit is reachable from
+	pointsAt string // revision the tag points at
+}
+
+// tagRef is a tag and the id of the commit it points to, directly or through an annotated tag. The
+// commit may be empty for other targets, eg: an annotated tag of an annotated tag.
+type tagRef struct {
+	name   string
+	commit string
+}
+
+// openRepository opens the git directory with the named backend, or the default backend if
+// backend is empty
+func openRepository(backend, gitDir string) (repository, error) {
+	if backend == "" {
+		backend = defaultBackend
+	}
+
+	open, ok := backends[backend]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend '%s'; must be one of %v", backend, backendNames())
+	}
+	return open(gitDir)
+}
+
+// backendNames returns the sorted names of the backends
+func backendNames() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cliRepository is the repository backend running the git CLI through git-module
+type cliRepository struct {
+	*git.Repository
+}
+
+func openCLIRepository(gitDir string) (repository, error) {
+	repo, err := git.Open(gitDir)
+	if err != nil {
+		return nil, err
+	}
+	return cliRepository{repo}, nil
+}
+
+func (c cliRepository) Tags(filter tagFilter) ([]string, error) {
+	opts := git.TagsOptions{Pattern: filter.pattern}
+	if filter.merged != "" {
+		opts.Args = append(opts.Args, "--merged", filter.merged)
+	}
+	if filter.pointsAt != "" {
+		opts.Args = append(opts.Args, "--points-at", filter.pointsAt)
+	}
+	return c.Repository.Tags(opts)
+}
+
+// tagRefsFormat is the for-each-ref format of a tagRef: the ref name, the type and id of the object
+// it points to and, for annotated tags, the type and id of the tagged object
+const tagRefsFormat = "--format=%(refname)%00%(objecttype)%00%(objectname)%00%(*objecttype)%00%(*objectname)"
+
+func (c cliRepository) TagRefs(filter tagFilter) ([]tagRef, error) {
+	cmd := git.NewCommand("for-each-ref", tagRefsFormat)
+	if filter.merged != "" {
+		cmd.AddArgs("--merged", filter.merged)
+	}
+	if filter.pointsAt != "" {
+		cmd.AddArgs("--points-at", filter.pointsAt)
+	}
+	if filter.pattern != "" {
+		cmd.AddArgs(git.RefsTags + filter.pattern)
+	} else {
+		cmd.AddArgs(strings.TrimSuffix(git.RefsTags, "/"))
+	}
+
+	out, err := cmd.RunInDir(c.Path())
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []tagRef
+	for _, line := range bytes.Split(bytes.TrimSpace(out), []byte("\n")) {
+		fields := strings.Split(string(line), "\x00")
+		if len(fields) != 5 {
+			continue
+		}
+
+		ref := tagRef{name: strings.TrimPrefix(fields[0], git.RefsTags)}
+		switch {
+		case fields[1] == "commit":
+			ref.commit = fields[2]
+		case fields[1] == "tag" && fields[3] == "commit":
+			ref.commit = fields[4]
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (c cliRepository) CommitByRevision(rev string) (*git.Commit, error) {
+	return c.Repository.CommitByRevision(rev)
+}
+
+func (c cliRepository) BranchCommit(branch string) (*git.Commit, error) {
+	return c.Repository.BranchCommit(branch)
+}
+
+func (c cliRepository) RevList(refspecs []string) ([]*git.Commit, error) {
+	return c.Repository.RevList(refspecs)
+}
+
+func (c cliRepository) CreateTag(name, rev string, opts git.CreateTagOptions) error {
+	return c.Repository.CreateTag(name, rev, opts)
+}
+
+func (c cliRepository) DeleteTag(name string) error {
+	return c.Repository.DeleteTag(name)
+}
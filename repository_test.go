@@ -0,0 +1,90 @@
+package autotag
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert"
+	"github.com/gogs/git-module"
+)
+
+// forEachBackend runs test as a subtest named after each backend
+func forEachBackend(t *testing.T, test func(t *testing.T, backend string)) {
+	for _, backend := range backendNames() {
+		backend := backend
+		t.Run(backend, func(t *testing.T) {
+			test(t, backend)
+		})
+	}
+}
+
+func TestTagRefs(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "master")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+
+		seedTestRepo(t, "v1.0.0", repo)
+		runGit(t, tr, "tag", "-a", "v1.0.0-rc.1", "-m", "release candidate")
+		updateReadme(t, repo, "fix a bug")
+		runGit(t, tr, "tag", "-a", "v1.0.1", "-m", "release")
+		runGit(t, tr, "checkout", "-q", "-b", "feature", "master~1")
+		updateReadme(t, repo, "add a feature")
+		runGit(t, tr, "tag", "v1.1.0-alpha")
+
+		first := runGit(t, tr, "rev-parse", "master~1")
+		second := runGit(t, tr, "rev-parse", "master")
+		feature := runGit(t, tr, "rev-parse", "feature")
+
+		r, err := openRepository(backend, filepath.Join(tr, ".git"))
+		checkFatal(t, err)
+
+		tests := []struct {
+			name     string
+			filter   tagFilter
+			expected []tagRef
+		}{
+			{
+				name: "all tags",
+				expected: []tagRef{
+					{name: "v1.0.0", commit: first},
+					{name: "v1.0.0-rc.1", commit: first},
+					{name: "v1.0.1", commit: second},
+					{name: "v1.1.0-alpha", commit: feature},
+				},
+			},
+			{
+				name:   "pattern",
+				filter: tagFilter{pattern: "v1.0.0-rc.*"},
+				expected: []tagRef{
+					{name: "v1.0.0-rc.1", commit: first},
+				},
+			},
+			{
+				name:   "merged",
+				filter: tagFilter{merged: "master"},
+				expected: []tagRef{
+					{name: "v1.0.0", commit: first},
+					{name: "v1.0.0-rc.1", commit: first},
+					{name: "v1.0.1", commit: second},
+				},
+			},
+			{
+				name:   "points at",
+				filter: tagFilter{pointsAt: first},
+				expected: []tagRef{
+					{name: "v1.0.0", commit: first},
+					{name: "v1.0.0-rc.1", commit: first},
+				},
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				refs, err := r.TagRefs(tc.filter)
+				checkFatal(t, err)
+				assert.Equal(t, tc.expected, refs)
+			})
+		}
+	})
+}
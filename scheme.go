@@ -0,0 +1,189 @@
+package autotag
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hashicorp/go-version"
+)
+
+// BumpLevel is the version bump requested by a commit
+type BumpLevel int
+
+const (
+	// BumpUnspecified means the commit doesn't request a bump. A patch bump is applied when no
+	// commit requests a bump.
+	BumpUnspecified BumpLevel = iota
+
+	// BumpPatch requests a patch version bump, eg: 1.2.3 -> 1.2.4
+	BumpPatch
+
+	// BumpMinor requests a minor version bump, eg: 1.2.3 -> 1.3.0
+	BumpMinor
+
+	// BumpMajor requests a major version bump, eg: 1.2.3 -> 2.0.0
+	BumpMajor
+
+	// BumpNone means the commit doesn't warrant a release, eg: a documentation change. Like
+	// BumpUnspecified it doesn't bump the version itself.
+	BumpNone
+)
+
+func (l BumpLevel) String() string {
+	switch l {
+	case BumpPatch:
+		return "patch"
+	case BumpMinor:
+		return "minor"
+	case BumpMajor:
+		return "major"
+	case BumpNone:
+		return "none"
+	default:
+		return "unspecified"
+	}
+}
+
+// initialDevelopment returns the level applied instead of l to a 0.y.z version when
+// GitRepoConfig.InitialDevelopment is set: major bumps become minor and minor bumps become patch.
+func (l BumpLevel) initialDevelopment() BumpLevel {
+	switch l {
+	case BumpMajor:
+		return BumpMinor
+	case BumpMinor:
+		return BumpPatch
+	default:
+		return l
+	}
+}
+
+// parseBumpLevel returns the level named "major", "minor" or "patch"
+func parseBumpLevel(name string) (BumpLevel, error) {
+	for _, l := range []BumpLevel{BumpPatch, BumpMinor, BumpMajor} {
+		if l.String() == name {
+			return l, nil
+		}
+	}
+	return BumpUnspecified, fmt.Errorf("bump '%s' is not valid; must be (major|minor|patch)", name)
+}
+
+// bumpBetween returns the level of the bump from one version to a higher one
+func bumpBetween(from, to *version.Version) BumpLevel {
+	f, t := from.Segments(), to.Segments()
+	switch {
+	case t[0] != f[0]:
+		return BumpMajor
+	case t[1] != f[1]:
+		return BumpMinor
+	default:
+		return BumpPatch
+	}
+}
+
+// bumper returns the bumper applying the level, or nil if the level doesn't bump the version
+func (l BumpLevel) bumper() bumper {
+	switch l {
+	case BumpPatch:
+		return patchBumper
+	case BumpMinor:
+		return minorBumper
+	case BumpMajor:
+		return majorBumper
+	default:
+		return nil
+	}
+}
+
+// Scheme is a commit message scheme, which determines the version bump requested by a commit.
+// Schemes are made available to GitRepoConfig.Scheme with RegisterScheme.
+type Scheme interface {
+	// Bump returns the version bump requested by the given commit message.
+	Bump(message string) BumpLevel
+}
+
+// SchemeFunc is an adapter to allow the use of ordinary functions as a Scheme.
+type SchemeFunc func(message string) BumpLevel
+
+// Bump calls f(message).
+func (f SchemeFunc) Bump(message string) BumpLevel {
+	return f(message)
+}
+
+// RuleScheme is implemented by schemes that can describe which of their rules matched a commit
+// message. The description is shown by `autotag explain`.
+type RuleScheme interface {
+	Scheme
+
+	// Rule returns the version bump requested by the given commit message and a short description
+	// of the rule that matched, or an empty string if no rule matched.
+	Rule(message string) (BumpLevel, string)
+}
+
+// ruleSchemeFunc is an adapter to allow the use of ordinary functions as a RuleScheme.
+type ruleSchemeFunc func(message string) (BumpLevel, string)
+
+// Bump returns the bump level of f(message).
+func (f ruleSchemeFunc) Bump(message string) BumpLevel {
+	level, _ := f(message)
+	return level
+}
+
+// Rule calls f(message).
+func (f ruleSchemeFunc) Rule(message string) (BumpLevel, string) {
+	return f(message)
+}
+
+var (
+	schemesMu sync.RWMutex
+	schemes   = make(map[string]Scheme)
+)
+
+func init() {
+	RegisterScheme("autotag", ruleSchemeFunc(parseAutotagCommit))
+	RegisterScheme("conventional", conventionalScheme(defaultConventionalTypes))
+}
+
+// RegisterScheme makes a commit message scheme available by name to GitRepoConfig.Scheme. If
+// RegisterScheme is called twice with the same name or if scheme is nil, it panics.
+func RegisterScheme(name string, scheme Scheme) {
+	schemesMu.Lock()
+	defer schemesMu.Unlock()
+
+	if scheme == nil {
+		panic("autotag: RegisterScheme scheme is nil")
+	}
+	if _, dup := schemes[name]; dup {
+		panic("autotag: RegisterScheme called twice for scheme " + name)
+	}
+	schemes[name] = scheme
+}
+
+// Schemes returns a sorted list of the names of the registered schemes.
+func Schemes() []string {
+	schemesMu.RLock()
+	defer schemesMu.RUnlock()
+
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupScheme returns the scheme registered with name. An empty name selects the "autotag" scheme.
+func lookupScheme(name string) (Scheme, error) {
+	if name == "" {
+		name = "autotag"
+	}
+
+	schemesMu.RLock()
+	s, ok := schemes[name]
+	schemesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown scheme '%s'; must be one of %v", name, Schemes())
+	}
+	return s, nil
+}
@@ -0,0 +1,75 @@
+package autotag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert"
+)
+
+func init() {
+	// a custom scheme, as a library user would register it
+	RegisterScheme("test-jira", SchemeFunc(func(msg string) BumpLevel {
+		switch {
+		case strings.HasPrefix(msg, "BREAKING-"):
+			return BumpMajor
+		case strings.HasPrefix(msg, "STORY-"):
+			return BumpMinor
+		case strings.HasPrefix(msg, "CHORE-"):
+			return BumpNone
+		}
+		return BumpUnspecified
+	}))
+}
+
+func TestRegisteredScheme(t *testing.T) {
+	tests := []struct {
+		name        string
+		nextCommit  string
+		expectedTag string
+	}{
+		{
+			name:        "major bump",
+			nextCommit:  "BREAKING-12 remove the v1 API",
+			expectedTag: "v2.0.0",
+		},
+		{
+			name:        "minor bump",
+			nextCommit:  "STORY-34 add an endpoint",
+			expectedTag: "v1.1.0",
+		},
+		{
+			name:        "fallback to patch bump",
+			nextCommit:  "[major] not this scheme's syntax",
+			expectedTag: "v1.0.1",
+		},
+	}
+
+	forEachBackend(t, func(t *testing.T, backend string) {
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				r := newTestRepo(t, testRepoSetup{
+					scheme:     "test-jira",
+					nextCommit: tc.nextCommit,
+					initialTag: "v1.0.0",
+					backend:    backend,
+				})
+				defer cleanupTestRepo(t, gitModuleRepo(t, &r))
+
+				assert.Equal(t, tc.expectedTag, r.tagName())
+			})
+		}
+	})
+}
+
+func TestRegisterScheme(t *testing.T) {
+	assert.Contains(t, Schemes(), "autotag")
+	assert.Contains(t, Schemes(), "conventional")
+
+	assert.Panics(t, func() {
+		RegisterScheme("autotag", ruleSchemeFunc(parseAutotagCommit))
+	})
+	assert.Panics(t, func() {
+		RegisterScheme("nil-scheme", nil)
+	})
+}
@@ -0,0 +1,107 @@
+package autotag
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gogs/git-module"
+)
+
+const (
+	// deepenRemote is the remote history is fetched from when deepening a shallow clone
+	deepenRemote = "origin"
+
+	// initialDeepen is the number of commits the first deepening fetches, doubled on each attempt
+	initialDeepen = 50
+)
+
+// shallowCloneError explains how to deal with a shallow clone
+const shallowCloneError = `repository is a shallow clone, so version tags and the history since the last one may be missing;
+fetch them with 'git fetch --tags --unshallow' or enable deepening of shallow clones`
+
+// isShallow reports whether the repository is a shallow clone
+func (r *GitRepo) isShallow() bool {
+	_, err := os.Stat(filepath.Join(commonGitDir(r.gitDir), "shallow"))
+	return err == nil
+}
+
+// commonGitDir returns the git directory holding the objects and refs shared by the linked
+// worktrees of a repository, which is gitDir itself for the main worktree
+func commonGitDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+
+	dir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(gitDir, dir)
+	}
+	return filepath.Clean(dir)
+}
+
+// checkShallow returns an error for a shallow clone, unless deepening is enabled, in which case
+// tags are fetched and the history is deepened until the version tag parseTags picks is reachable
+// from the branch.
+func (r *GitRepo) checkShallow() error {
+	if !r.isShallow() {
+		return nil
+	}
+	if !r.deepenShallow {
+		return fmt.Errorf(shallowCloneError)
+	}
+
+	log.Printf("Fetching tags from %s", deepenRemote)
+	if _, err := git.NewCommand("fetch", "--tags", deepenRemote).RunInDir(r.remoteDir()); err != nil {
+		return fmt.Errorf("error fetching tags from %s: %s", deepenRemote, err)
+	}
+
+	for depth := initialDeepen; r.isShallow(); depth *= 2 {
+		found, err := r.versionTagReachable()
+		if err != nil {
+			return err
+		}
+		if found {
+			return nil
+		}
+
+		log.Printf("Deepening history by %d commits", depth)
+		if _, err := git.NewCommand("fetch", fmt.Sprintf("--deepen=%d", depth), deepenRemote).RunInDir(r.remoteDir()); err != nil {
+			return fmt.Errorf("error deepening history from %s: %s", deepenRemote, err)
+		}
+	}
+	return nil
+}
+
+// versionTagReachable reports whether the highest stable version tag, the one parseTags picks, is
+// reachable from the branch. With allTags that tag may have been fetched without its history.
+func (r *GitRepo) versionTagReachable() (bool, error) {
+	tags, err := r.versionTags(r.allTags)
+	if err != nil {
+		return false, err
+	}
+
+	var highest *versionTag
+	for i, tag := range tags {
+		if tag.version.Prerelease() == "" && (highest == nil || tag.version.GreaterThan(highest.version)) {
+			highest = &tags[i]
+		}
+	}
+	if highest == nil || !r.allTags {
+		return highest != nil, nil
+	}
+
+	reachable, err := r.versionTags(false)
+	if err != nil {
+		return false, err
+	}
+	for _, tag := range reachable {
+		if tag.name == highest.name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,88 @@
+package autotag
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert"
+	"github.com/gogs/git-module"
+)
+
+// newShallowClone clones the test repo with the given depth and without tags, like CI checkouts
+func newShallowClone(t *testing.T, repo *git.Repository, depth string) string {
+	clone := filepath.Join(t.TempDir(), "clone")
+	runGit(t, repoRoot(repo), "clone", "--depth", depth, "--no-tags", "file://"+repoRoot(repo), clone)
+	return clone
+}
+
+func TestShallowClone(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "master")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+
+		// the history is longer than the first deepening, both before and after the version tag, so
+		// the clone is deepened more than once and stays shallow once the tag is reachable
+		seedTestRepo(t, "not-a-version", repo)
+		for i := 0; i < 2*initialDeepen; i++ {
+			updateReadme(t, repo, fmt.Sprintf("old work %d", i))
+		}
+		makeTag(repo, "v1.0.0")
+		updateReadme(t, repo, "#minor add a feature")
+		for i := 0; i < initialDeepen+10; i++ {
+			updateReadme(t, repo, fmt.Sprintf("fix a bug %d", i))
+		}
+
+		tests := []struct {
+			name    string
+			allTags bool
+		}{
+			{name: "tags reachable from the branch"},
+			{name: "all tags", allTags: true},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				clone := newShallowClone(t, repo, "2")
+				shallow := filepath.Join(clone, ".git", "shallow")
+				_, err := os.Stat(shallow)
+				checkFatal(t, err)
+
+				_, err = NewRepo(GitRepoConfig{RepoPath: clone, Branch: "master", Prefix: true, AllTags: tc.allTags, Backend: backend})
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "repository is a shallow clone")
+
+				r, err := NewRepo(GitRepoConfig{RepoPath: clone, Branch: "master", Prefix: true, AllTags: tc.allTags, DeepenShallow: true, Backend: backend})
+				checkFatal(t, err)
+				assert.Equal(t, "1.1.0", r.LatestVersion())
+				assert.Equal(t, "1.0.0", r.currentVersion.String())
+
+				_, err = os.Stat(shallow)
+				assert.NoError(t, err, "expected the clone to stay shallow once the version tag is reachable")
+			})
+		}
+	})
+}
+
+func TestShallowCloneWithoutVersionTags(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		tr := createTestRepo(t, "master")
+		repo, err := git.Open(tr)
+		checkFatal(t, err)
+
+		seedTestRepo(t, "not-a-version", repo)
+		updateReadme(t, repo, "#minor add a feature")
+		updateReadme(t, repo, "fix a bug")
+
+		clone := newShallowClone(t, repo, "1")
+
+		r, err := NewRepo(GitRepoConfig{RepoPath: clone, Branch: "master", Prefix: true, DeepenShallow: true, InitialVersion: "0.1.0", Backend: backend})
+		checkFatal(t, err)
+		assert.Equal(t, "0.1.0", r.LatestVersion())
+
+		_, err = os.Stat(filepath.Join(clone, ".git", "shallow"))
+		assert.True(t, os.IsNotExist(err), "expected the whole history to be fetched")
+	})
+}
@@ -0,0 +1,58 @@
+package autotag
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gogs/git-module"
+)
+
+// createSignedTag creates a signed tag on branchID and verifies the signature. A tag that fails
+// verification is deleted so that it is never reported as a successful release.
+func (r *GitRepo) createSignedTag(tagName string) error {
+	msg, err := r.tagMessageFor(tagName)
+	if err != nil {
+		return err
+	}
+
+	cmd := r.signingCommand("tag", "--message", msg)
+	if r.signingKey != "" {
+		cmd.AddArgs("--local-user", r.signingKey)
+	} else {
+		cmd.AddArgs("--sign")
+	}
+	cmd.AddArgs(tagName, r.branchID)
+
+	log.Println("Writing signed Tag", tagName)
+	if _, err := cmd.RunInDir(r.gitDir); err != nil {
+		return fmt.Errorf("error creating signed tag: %s", err)
+	}
+
+	if err := r.verifyTag(tagName); err != nil {
+		if derr := r.repo.DeleteTag(tagName); derr != nil {
+			log.Printf("error deleting unverified tag '%s': %s", tagName, derr)
+		}
+		return err
+	}
+	return nil
+}
+
+// verifyTag checks the signature of a tag with `git verify-tag`
+func (r *GitRepo) verifyTag(tagName string) error {
+	log.Println("Verifying Tag", tagName)
+	out, err := r.signingCommand("verify-tag", "--verbose", tagName).RunInDir(r.gitDir)
+	if err != nil {
+		return fmt.Errorf("error verifying signed tag '%s': %s", tagName, err)
+	}
+	log.Printf("%s", out)
+	return nil
+}
+
+// signingCommand returns a git command that honours the configured signing format
+func (r *GitRepo) signingCommand(args ...string) *git.Command {
+	cmd := git.NewCommand()
+	if r.signingFormat != "" {
+		cmd.AddArgs("-c", "gpg.format="+r.signingFormat)
+	}
+	return cmd.AddArgs(args...)
+}
@@ -0,0 +1,138 @@
+package autotag
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert"
+)
+
+// newGPGKey creates a throwaway GPG key in a temporary GNUPGHOME and returns its fingerprint
+func newGPGKey(t *testing.T) string {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not available")
+	}
+
+	// gpg-agent sockets live in GNUPGHOME and have a short maximum path length, so avoid t.TempDir()
+	home, err := os.MkdirTemp("", "gpg")
+	checkFatal(t, err)
+	t.Cleanup(func() {
+		_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		os.RemoveAll(home)
+	})
+	t.Setenv("GNUPGHOME", home)
+
+	cmd := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", "Autotag Test <autotag@example.com>", "default", "default", "never")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("gpg key generation failed: %s: %s", err, out)
+	}
+
+	out, err = exec.Command("gpg", "--batch", "--with-colons", "--list-secret-keys").Output()
+	checkFatal(t, err)
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "fpr:") {
+			return strings.Split(line, ":")[9]
+		}
+	}
+	t.Fatal("no gpg fingerprint found")
+	return ""
+}
+
+// newSSHKey creates a throwaway SSH key and configures git to trust it when verifying tags. It
+// returns the path to the private key.
+func newSSHKey(t *testing.T) string {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	dir := t.TempDir()
+	key := filepath.Join(dir, "id_ed25519")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "autotag@example.com", "-f", key).CombinedOutput()
+	if err != nil {
+		t.Fatalf("ssh key generation failed: %s: %s", err, out)
+	}
+
+	pub, err := os.ReadFile(key + ".pub")
+	checkFatal(t, err)
+	signers := filepath.Join(dir, "allowed_signers")
+	err = os.WriteFile(signers, []byte("* "+string(pub)), 0o644)
+	checkFatal(t, err)
+
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "gpg.ssh.allowedSignersFile")
+	t.Setenv("GIT_CONFIG_VALUE_0", signers)
+
+	return key
+}
+
+func TestSignedTag(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, backend string) {
+		t.Run("gpg", func(t *testing.T) {
+			key := newGPGKey(t)
+
+			r := newTestRepo(t, testRepoSetup{
+				nextCommit: "#minor add a feature",
+				initialTag: "v1.0.0",
+				signTag:    true,
+				signingKey: key,
+				backend:    backend,
+			})
+			defer cleanupTestRepo(t, gitModuleRepo(t, &r))
+
+			err := r.AutoTag()
+			assert.NoError(t, err)
+			assert.Equal(t, "tag", tagObjectType(t, gitModuleRepo(t, &r), "v1.1.0"))
+			assert.Contains(t, tagContents(t, gitModuleRepo(t, &r), "v1.1.0"), "-----BEGIN PGP SIGNATURE-----")
+		})
+
+		t.Run("ssh", func(t *testing.T) {
+			key := newSSHKey(t)
+
+			r := newTestRepo(t, testRepoSetup{
+				nextCommit:    "#minor add a feature",
+				initialTag:    "v1.0.0",
+				signTag:       true,
+				signingKey:    key,
+				signingFormat: "ssh",
+				backend:       backend,
+			})
+			defer cleanupTestRepo(t, gitModuleRepo(t, &r))
+
+			err := r.AutoTag()
+			assert.NoError(t, err)
+			assert.Equal(t, "tag", tagObjectType(t, gitModuleRepo(t, &r), "v1.1.0"))
+			assert.Contains(t, tagContents(t, gitModuleRepo(t, &r), "v1.1.0"), "-----BEGIN SSH SIGNATURE-----")
+		})
+
+		t.Run("unverifiable signature is rejected", func(t *testing.T) {
+			key := newSSHKey(t)
+
+			// an allowed signers file that trusts nobody
+			empty := filepath.Join(t.TempDir(), "allowed_signers")
+			err := os.WriteFile(empty, nil, 0o644)
+			checkFatal(t, err)
+			t.Setenv("GIT_CONFIG_VALUE_0", empty)
+
+			r := newTestRepo(t, testRepoSetup{
+				nextCommit:    "#minor add a feature",
+				initialTag:    "v1.0.0",
+				signTag:       true,
+				signingKey:    key,
+				signingFormat: "ssh",
+				backend:       backend,
+			})
+			defer cleanupTestRepo(t, gitModuleRepo(t, &r))
+
+			err = r.AutoTag()
+			assert.Error(t, err)
+
+			tags, err := r.repo.Tags(tagFilter{})
+			checkFatal(t, err)
+			assert.NotContains(t, tags, "v1.1.0")
+		})
+	})
+}